@@ -0,0 +1,169 @@
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"sort"
+)
+
+// Severity says how serious an error is, for logging and alerting
+type Severity int
+
+const (
+	SeverityInfo Severity = iota
+	SeverityWarning
+	SeverityError
+	SeverityCritical
+)
+
+func (s Severity) String() string {
+	switch s {
+	case SeverityInfo:
+		return "info"
+	case SeverityWarning:
+		return "warning"
+	case SeverityError:
+		return "error"
+	case SeverityCritical:
+		return "critical"
+	}
+	return fmt.Sprintf("severity(%d)", int(s))
+}
+
+// Kind is one entry in the error catalog. Codes are stable and meant for
+// machines; the template is the default human message.
+type Kind struct {
+	Code      string
+	Severity  Severity
+	Template  string // fmt format, filled in by New and Wrap
+	Retryable bool   // Trying the same operation again may succeed
+}
+
+// Error lets a Kind be used as an errors.Is target, matching any catalog
+// error with the same code
+func (k *Kind) Error() string {
+	return k.Code
+}
+
+// catalog holds every defined kind by code
+var catalog = make(map[string]*Kind)
+
+// Define adds a kind to the catalog. Codes must be unique, so it panics on
+// a duplicate; kinds are defined once, as package variables.
+func Define(code string, severity Severity, template string, retryable bool) *Kind {
+	if _, exists := catalog[code]; exists {
+		panic("error code defined twice: " + code)
+	}
+	k := &Kind{Code: code, Severity: severity, Template: template, Retryable: retryable}
+	catalog[code] = k
+	return k
+}
+
+// Lookup finds a kind by code
+func Lookup(code string) (*Kind, bool) {
+	k, ok := catalog[code]
+	return k, ok
+}
+
+// Catalog returns every kind, sorted by code, for documentation
+func Catalog() []*Kind {
+	kinds := make([]*Kind, 0, len(catalog))
+	for _, k := range catalog {
+		kinds = append(kinds, k)
+	}
+	sort.Slice(kinds, func(i, j int) bool { return kinds[i].Code < kinds[j].Code })
+	return kinds
+}
+
+// The error catalog
+var (
+	ErrDivisionByZero = Define("MATH_DIVISION_BY_ZERO", SeverityError, "division by zero", false)
+	ErrNotPositive    = Define("VALIDATION_NOT_POSITIVE", SeverityWarning, "%s must be positive", false)
+	ErrInvalidNumber  = Define("PARSE_INVALID_NUMBER", SeverityWarning, "%q is not a number", false)
+	ErrFileOpen       = Define("IO_FILE_OPEN", SeverityError, "failed to open %s", false)
+	ErrFileRead       = Define("IO_FILE_READ", SeverityError, "failed to read %s", true)
+)
+
+// Error is an error from the catalog
+type Error struct {
+	Kind    *Kind
+	Message string // The kind's template, filled in
+	Err     error  // Underlying cause, if any
+}
+
+// New creates a catalog error, filling the kind's template with args
+func New(kind *Kind, args ...any) *Error {
+	return &Error{Kind: kind, Message: fmt.Sprintf(kind.Template, args...)}
+}
+
+// Wrap creates a catalog error caused by err
+func Wrap(kind *Kind, err error, args ...any) *Error {
+	e := New(kind, args...)
+	e.Err = err
+	return e
+}
+
+func (e *Error) Error() string {
+	if e.Err != nil {
+		return fmt.Sprintf("[%s] %s: %v", e.Kind.Code, e.Message, e.Err)
+	}
+	return fmt.Sprintf("[%s] %s", e.Kind.Code, e.Message)
+}
+
+func (e *Error) Unwrap() error {
+	return e.Err
+}
+
+// Is matches any error of the same kind, whether the target is the Kind
+// itself or another *Error
+func (e *Error) Is(target error) bool {
+	switch t := target.(type) {
+	case *Kind:
+		return e.Kind.Code == t.Code
+	case *Error:
+		return e.Kind.Code == t.Kind.Code
+	}
+	return false
+}
+
+// MarshalJSON gives API clients the code and attributes, not just text
+func (e *Error) MarshalJSON() ([]byte, error) {
+	return json.Marshal(struct {
+		Code      string `json:"code"`
+		Severity  string `json:"severity"`
+		Message   string `json:"message"`
+		Retryable bool   `json:"retryable"`
+		Cause     string `json:"cause,omitempty"`
+	}{
+		Code:      e.Kind.Code,
+		Severity:  e.Kind.Severity.String(),
+		Message:   e.Message,
+		Retryable: e.Kind.Retryable,
+		Cause:     causeText(e.Err),
+	})
+}
+
+func causeText(err error) string {
+	if err == nil {
+		return ""
+	}
+	return err.Error()
+}
+
+// CodeOf returns the code of the outermost catalog error in err's chain,
+// or "" if there is none
+func CodeOf(err error) string {
+	var e *Error
+	if errors.As(err, &e) {
+		return e.Kind.Code
+	}
+	return ""
+}
+
+// IsRetryable reports whether the outermost catalog error in err's chain
+// is retryable. Errors outside the catalog are not.
+func IsRetryable(err error) bool {
+	var e *Error
+	return errors.As(err, &e) && e.Kind.Retryable
+}
@@ -1,12 +1,16 @@
 package main
 
 import (
+	"context"
+	"encoding/json"
 	"errors"
 	"fmt"
 	"io"
 	"os"
 	"strconv"
 	"strings"
+	"syscall"
+	"time"
 )
 
 // Example 10: Error Handling
@@ -15,15 +19,17 @@ import (
 // Standard error handling with return values
 func divide(a, b float64) (float64, error) {
 	if b == 0 {
-		return 0, errors.New("division by zero")
+		return 0, WithStack(New(ErrDivisionByZero))
 	}
 	return a / b, nil
 }
 
-// Custom error type
+// Custom error type. Err is its entry in the error catalog, so it can be
+// matched by code.
 type ValidationError struct {
 	Field   string
 	Message string
+	Err     error
 }
 
 // Implementation of the Error interface for custom error type
@@ -31,12 +37,18 @@ func (e ValidationError) Error() string {
 	return fmt.Sprintf("validation error on field %s: %s", e.Field, e.Message)
 }
 
+// Unwrap exposes the catalog error to errors.Is and errors.As
+func (e ValidationError) Unwrap() error {
+	return e.Err
+}
+
 // Function that returns our custom error type
 func validatePositive(value int, name string) error {
 	if value <= 0 {
 		return ValidationError{
 			Field:   name,
 			Message: "must be positive",
+			Err:     New(ErrNotPositive, name),
 		}
 	}
 	return nil
@@ -47,17 +59,17 @@ func parseAndMultiply(str string, factor int) (int, error) {
 	// Parse string to int
 	num, err := strconv.Atoi(str)
 	if err != nil {
-		return 0, fmt.Errorf("error parsing '%s': %w", str, err)
+		return 0, Errorf("error parsing '%s': %w", str, Wrap(ErrInvalidNumber, err, str))
 	}
 
 	// Validate the number
 	if err := validatePositive(num, "parsed number"); err != nil {
-		return 0, fmt.Errorf("validation failed: %w", err)
+		return 0, Errorf("validation failed: %w", err)
 	}
 
 	// Validate the factor
 	if err := validatePositive(factor, "factor"); err != nil {
-		return 0, fmt.Errorf("factor validation failed: %w", err)
+		return 0, Errorf("factor validation failed: %w", err)
 	}
 
 	return num * factor, nil
@@ -68,7 +80,7 @@ func readFile(path string) (string, error) {
 	// Open the file
 	file, err := os.Open(path)
 	if err != nil {
-		return "", fmt.Errorf("failed to open file: %w", err)
+		return "", WithStack(Wrap(ErrFileOpen, err, path))
 	}
 	// Important: defer the close operation to ensure it happens even if
 	// an error occurs later
@@ -80,7 +92,7 @@ func readFile(path string) (string, error) {
 	for {
 		n, err := file.Read(buf)
 		if err != nil && err != io.EOF {
-			return "", fmt.Errorf("failed to read file: %w", err)
+			return "", WithStack(Wrap(ErrFileRead, err, path))
 		}
 
 		if n == 0 {
@@ -93,6 +105,52 @@ func readFile(path string) (string, error) {
 	return sb.String(), nil
 }
 
+// Retry policy for file operations, which can fail transiently on
+// network filesystems
+var fileRetry = RetryOptions{
+	Policy:      DecorrelatedJitter{Base: time.Millisecond * 10, Max: time.Millisecond * 200},
+	MaxAttempts: 5,
+	MaxElapsed:  time.Second,
+	Classify:    isTransientIO,
+	OnAttempt:   logAttempt,
+}
+
+// Function that classifies I/O errors: catalog errors marked retryable,
+// and system errors that a network filesystem can return for a moment
+func isTransientIO(err error) bool {
+	if IsRetryable(err) {
+		return true
+	}
+	var errno syscall.Errno
+	if !errors.As(err, &errno) {
+		return false
+	}
+	switch errno {
+	case syscall.EIO, syscall.EAGAIN, syscall.EINTR, syscall.EBUSY, syscall.ESTALE, syscall.ETIMEDOUT:
+		return true
+	}
+	return false
+}
+
+// Function that logs each failed attempt
+func logAttempt(a Attempt) {
+	switch {
+	case !a.Retryable:
+		fmt.Printf("  attempt %d failed permanently: %v\n", a.Number, a.Err)
+	case a.Delay == 0:
+		fmt.Printf("  attempt %d failed, giving up: %v\n", a.Number, a.Err)
+	default:
+		fmt.Printf("  attempt %d failed, retrying in %v: %v\n", a.Number, a.Delay.Round(time.Millisecond), a.Err)
+	}
+}
+
+// Function that reads a file, retrying transient errors
+func readFileWithRetry(ctx context.Context, path string) (string, error) {
+	return RetryValue(ctx, fileRetry, func(context.Context) (string, error) {
+		return readFile(path)
+	})
+}
+
 // Panic and recover demonstration
 func safeDivision(a, b int) (result int) {
 	// Set up deferred recovery function
@@ -112,9 +170,11 @@ func safeDivision(a, b int) (result int) {
 }
 
 // Function that creates a temporary file and ensures cleanup
-func workWithTempFile() error {
-	// Create a temporary file
-	tempFile, err := os.CreateTemp("", "example")
+func workWithTempFile(ctx context.Context) error {
+	// Create a temporary file, retrying transient errors
+	tempFile, err := RetryValue(ctx, fileRetry, func(context.Context) (*os.File, error) {
+		return os.CreateTemp("", "example")
+	})
 	if err != nil {
 		return fmt.Errorf("failed to create temp file: %w", err)
 	}
@@ -126,8 +186,12 @@ func workWithTempFile() error {
 		fmt.Println("Temporary file cleaned up:", tempFile.Name())
 	}()
 
-	// Write something to the file
-	_, err = tempFile.Write([]byte("This is a temporary file."))
+	// Write something to the file. WriteAt makes a retried write replace a
+	// partial one instead of appending to it.
+	err = Retry(ctx, fileRetry, func(context.Context) error {
+		_, err := tempFile.WriteAt([]byte("This is a temporary file."), 0)
+		return err
+	})
 	if err != nil {
 		return fmt.Errorf("failed to write to temp file: %w", err)
 	}
@@ -136,6 +200,84 @@ func workWithTempFile() error {
 	return nil
 }
 
+// Function that shows retries against a file that fails transiently
+func retryExample() {
+	file, err := os.CreateTemp("", "retry")
+	if err != nil {
+		fmt.Println("Error:", err)
+		return
+	}
+	defer os.Remove(file.Name())
+	file.WriteString("settings=on")
+	file.Close()
+
+	// Fail the first two opens as a network filesystem might
+	calls := 0
+	flakyRead := func(ctx context.Context) (string, error) {
+		calls++
+		if calls <= 2 {
+			err := &os.PathError{Op: "open", Path: file.Name(), Err: syscall.EIO}
+			return "", WithStack(Wrap(ErrFileOpen, err, file.Name()))
+		}
+		return readFile(file.Name())
+	}
+	content, err := RetryValue(context.Background(), fileRetry, flakyRead)
+	fmt.Printf("Read %q after %d attempts, error: %v\n", content, calls, err)
+
+	// An error that keeps happening uses up the attempts
+	alwaysBusy := func(context.Context) error {
+		return &os.PathError{Op: "read", Path: "/mnt/share/data", Err: syscall.EBUSY}
+	}
+	opts := fileRetry
+	opts.Policy = Constant{Wait: time.Millisecond * 5}
+	opts.MaxAttempts = 3
+	err = Retry(context.Background(), opts, alwaysBusy)
+	fmt.Println("Exhausted:", errors.Is(err, ErrRetriesExhausted), "busy:", errors.Is(err, syscall.EBUSY))
+
+	// Cancelling the context stops the waiting
+	ctx, cancel := context.WithTimeout(context.Background(), time.Millisecond*30)
+	defer cancel()
+	opts.Policy = Constant{Wait: time.Millisecond * 20}
+	opts.MaxAttempts = 0
+	opts.OnAttempt = nil
+	err = Retry(ctx, opts, alwaysBusy)
+	fmt.Println("Error:", err)
+
+	// Once the context is done, fn is not called at all. A nil Policy
+	// uses DefaultPolicy.
+	called := false
+	err = Retry(ctx, RetryOptions{}, func(context.Context) error {
+		called = true
+		return nil
+	})
+	fmt.Println("Called after cancel:", called, "error:", err)
+
+	// Permanent overrides the classifier
+	err = Retry(context.Background(), fileRetry, func(context.Context) error {
+		return Permanent(&os.PathError{Op: "open", Path: "/mnt/share/locked", Err: syscall.EIO})
+	})
+	fmt.Println("Stopped at once:", err)
+
+	// The delays each policy would use
+	policies := []struct {
+		name   string
+		policy Policy
+	}{
+		{"constant", Constant{Wait: time.Millisecond * 100}},
+		{"exponential", Exponential{Initial: time.Millisecond * 10, Max: time.Second}},
+		{"decorrelated jitter", DecorrelatedJitter{Base: time.Millisecond * 10, Max: time.Second}},
+	}
+	for _, p := range policies {
+		var delays []string
+		var prev time.Duration
+		for attempt := 1; attempt <= 6; attempt++ {
+			prev = p.policy.Delay(attempt, prev)
+			delays = append(delays, prev.Round(time.Millisecond).String())
+		}
+		fmt.Printf("  %-20s %s\n", p.name, strings.Join(delays, " "))
+	}
+}
+
 func main() {
 	fmt.Println("Error Handling in Go:")
 
@@ -193,10 +335,62 @@ func main() {
 		fmt.Println("Error:", err)
 	}
 
+	// Error catalog: match by code through any wrapping
+	fmt.Println("\n=== Error Catalog ===")
+	for _, input := range []string{"abc", "-5"} {
+		_, err = parseAndMultiply(input, 5)
+		fmt.Printf("Input %q: code=%s not-a-number=%t not-positive=%t\n", input, CodeOf(err),
+			errors.Is(err, ErrInvalidNumber), errors.Is(err, ErrNotPositive))
+	}
+
+	_, err = divide(1, 0)
+	wrapped := fmt.Errorf("computing ratio: %w", fmt.Errorf("report: %w", err))
+	var catalogErr *Error
+	if errors.As(wrapped, &catalogErr) {
+		fmt.Printf("Code: %s, Severity: %s, Retryable: %t\n",
+			catalogErr.Kind.Code, catalogErr.Kind.Severity, catalogErr.Kind.Retryable)
+	}
+	body, _ := json.Marshal(catalogErr)
+	fmt.Println("JSON:", string(body))
+
+	for _, kind := range Catalog() {
+		fmt.Printf("  %-24s %-8s retryable=%-5t %q\n", kind.Code, kind.Severity, kind.Retryable, kind.Template)
+	}
+
+	// Validation framework: every violation at once, with nested paths
+	fmt.Println("\n=== Validation Framework ===")
+	valid := Employee{
+		Person:     Person{FirstName: "Sarah", LastName: "Johnson", Age: 32},
+		EmployeeID: "E12345",
+		Department: "Engineering",
+		HireDate:   time.Date(2018, time.March, 15, 0, 0, 0, 0, time.UTC),
+	}
+	fmt.Println("Valid employee:", Validate(valid))
+
+	invalid := Employee{
+		Person:     Person{FirstName: "Sarah", Age: 0},
+		Department: " ",
+		HireDate:   time.Now().AddDate(1, 0, 0),
+	}
+	err = Validate(invalid)
+	var violations ValidationErrors
+	if errors.As(err, &violations) {
+		fmt.Printf("Found %d violations:\n", len(violations))
+		for _, v := range violations {
+			fmt.Printf("  %-24s %-26s (%s)\n", v.Field, v.Message, CodeOf(v))
+		}
+	}
+	fmt.Println("Any value out of range:", errors.Is(err, ErrOutOfRange))
+	body, _ = json.Marshal(err)
+	fmt.Println("JSON:", string(body))
+
+	var missing *Employee
+	fmt.Println("Nil employee:", Validate(missing))
+
 	// Defer for cleanup
 	fmt.Println("\n=== Defer for Cleanup ===")
 	// This will likely fail, but cleanup will still happen
-	content, err := readFile("nonexistent_file.txt")
+	content, err := readFileWithRetry(context.Background(), "nonexistent_file.txt")
 	if err != nil {
 		fmt.Println("Read file error:", err)
 	} else {
@@ -204,11 +398,28 @@ func main() {
 	}
 
 	// Try to work with a temporary file
-	err = workWithTempFile()
+	err = workWithTempFile(context.Background())
 	if err != nil {
 		fmt.Println("Temp file error:", err)
 	}
 
+	// Stack traces: %v prints the message, %+v adds where it came from
+	fmt.Println("\n=== Stack Traces ===")
+	_, err = parseAndMultiply("-5", 3)
+	err = Errorf("handling request: %w", err)
+	fmt.Printf("%v\n\n", err)
+	fmt.Printf("%+v", err)
+	fmt.Println("Still matches by code:", errors.Is(err, ErrNotPositive))
+
+	// Plain fmt.Errorf hides the stack from %+v, but it can still be found
+	_, err = readFile("nonexistent_file.txt")
+	err = fmt.Errorf("loading config: %w", err)
+	fmt.Printf("%v\n%s", err, StackTrace(err))
+
+	// Retry: transient errors are retried, permanent ones are not
+	fmt.Println("\n=== Retry ===")
+	retryExample()
+
 	// Panic and recover
 	fmt.Println("\n=== Panic and Recover ===")
 	fmt.Println("Safe division 10/2:", safeDivision(10, 2))
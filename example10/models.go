@@ -0,0 +1,38 @@
+package main
+
+import "time"
+
+// Types mirroring example 7, used by the validation examples
+
+// Basic struct definition
+type Person struct {
+	FirstName string
+	LastName  string
+	Age       int
+}
+
+// Struct with embedded struct
+type Employee struct {
+	Person     // Embedded struct
+	EmployeeID string
+	Title      string
+	Department string
+	HireDate   time.Time
+}
+
+// Validate checks every field of a person
+func (p Person) Validate(v *Validator) {
+	v.Required("FirstName", p.FirstName)
+	v.Required("LastName", p.LastName)
+	v.Range("Age", p.Age, 1, 150)
+}
+
+// Validate checks an employee, including the embedded person
+func (e Employee) Validate(v *Validator) {
+	v.Nested("Person", e.Person)
+	v.Required("EmployeeID", e.EmployeeID)
+	v.Required("Department", e.Department)
+	if e.HireDate.After(time.Now()) {
+		v.Add("HireDate", "must not be in the future", New(ErrFutureDate, v.path("HireDate")))
+	}
+}
@@ -0,0 +1,195 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math"
+	"math/rand"
+	"time"
+)
+
+// ErrRetriesExhausted is returned, along with the last error, when the
+// attempt or time budget runs out
+var ErrRetriesExhausted = errors.New("retries exhausted")
+
+// Policy decides how long to wait before the next attempt. attempt counts
+// from 1 for the wait after the first failure; prev is the previous wait,
+// zero the first time.
+type Policy interface {
+	Delay(attempt int, prev time.Duration) time.Duration
+}
+
+// Constant waits the same time between every attempt
+type Constant struct {
+	Wait time.Duration
+}
+
+func (c Constant) Delay(int, time.Duration) time.Duration {
+	return c.Wait
+}
+
+// Exponential multiplies the wait after every attempt, up to Max
+type Exponential struct {
+	Initial    time.Duration
+	Max        time.Duration // Zero means no cap
+	Multiplier float64       // Defaults to 2
+}
+
+func (e Exponential) Delay(attempt int, _ time.Duration) time.Duration {
+	multiplier := e.Multiplier
+	if multiplier == 0 {
+		multiplier = 2
+	}
+	d := float64(e.Initial) * math.Pow(multiplier, float64(attempt-1))
+	return capDelay(d, e.Max)
+}
+
+// capDelay converts d to a Duration no larger than limit, if limit is set,
+// and without overflowing
+func capDelay(d float64, limit time.Duration) time.Duration {
+	if limit > 0 && d > float64(limit) {
+		return limit
+	}
+	if d >= math.MaxInt64 {
+		return time.Duration(math.MaxInt64)
+	}
+	return time.Duration(d)
+}
+
+// DecorrelatedJitter picks each wait at random between Base and three
+// times the previous wait, capped at Max. Clients that failed together
+// spread out instead of retrying in lockstep.
+type DecorrelatedJitter struct {
+	Base time.Duration // Must be above zero
+	Max  time.Duration // Zero means no cap
+}
+
+func (j DecorrelatedJitter) Delay(_ int, prev time.Duration) time.Duration {
+	upper := max(float64(prev)*3, float64(j.Base))
+	d := float64(j.Base) + rand.Float64()*(upper-float64(j.Base))
+	return capDelay(d, j.Max)
+}
+
+// Attempt describes one failed call, for logging
+type Attempt struct {
+	Number    int
+	Err       error
+	Retryable bool
+	Delay     time.Duration // Wait before the next attempt; zero if none follows
+	Elapsed   time.Duration
+}
+
+// DefaultPolicy is used when RetryOptions.Policy is nil
+var DefaultPolicy Policy = Exponential{Initial: 100 * time.Millisecond, Max: 10 * time.Second}
+
+// RetryOptions configures Retry. The limits are optional, but without
+// either a retryable error is retried forever.
+type RetryOptions struct {
+	Policy      Policy        // Defaults to DefaultPolicy
+	MaxAttempts int           // Total calls, including the first
+	MaxElapsed  time.Duration // No attempt is started after this much time
+	// Classify reports whether an error is worth retrying. Defaults to the
+	// error catalog's IsRetryable.
+	Classify  func(error) bool
+	OnAttempt func(Attempt) // Called after every failed call
+}
+
+// permanentError marks an error that must not be retried
+type permanentError struct{ err error }
+
+func (e permanentError) Error() string { return e.err.Error() }
+func (e permanentError) Unwrap() error { return e.err }
+
+// Permanent marks err so Retry stops at once, whatever Classify says
+func Permanent(err error) error {
+	if err == nil {
+		return nil
+	}
+	return permanentError{err}
+}
+
+// Retry calls fn until it succeeds, fails with an error that is not
+// retryable, or runs out of attempts, time or context. fn is never called
+// once ctx is done.
+func Retry(ctx context.Context, opts RetryOptions, fn func(ctx context.Context) error) error {
+	_, err := RetryValue(ctx, opts, func(ctx context.Context) (struct{}, error) {
+		return struct{}{}, fn(ctx)
+	})
+	return err
+}
+
+// RetryValue is Retry for functions that return a value
+func RetryValue[T any](ctx context.Context, opts RetryOptions, fn func(ctx context.Context) (T, error)) (T, error) {
+	classify := opts.Classify
+	if classify == nil {
+		classify = IsRetryable
+	}
+	policy := opts.Policy
+	if policy == nil {
+		policy = DefaultPolicy
+	}
+	start := time.Now()
+	var delay time.Duration
+	var last error
+
+	for attempt := 1; ; attempt++ {
+		if ctxErr := ctx.Err(); ctxErr != nil {
+			var zero T
+			if last == nil {
+				return zero, fmt.Errorf("retry cancelled before the first attempt: %w", ctxErr)
+			}
+			return zero, fmt.Errorf("retry cancelled after %d attempts: %w (last error: %w)", attempt-1, ctxErr, last)
+		}
+
+		value, err := fn(ctx)
+		if err == nil {
+			return value, nil
+		}
+
+		var permanent permanentError
+		isPermanent := errors.As(err, &permanent)
+		retryable := !isPermanent && classify(err)
+		if isPermanent {
+			err = permanent.err
+		}
+
+		// Work out whether and how long to wait before the next attempt
+		elapsed := time.Since(start)
+		next := time.Duration(0)
+		var stop error
+		switch {
+		case !retryable:
+		case opts.MaxAttempts > 0 && attempt >= opts.MaxAttempts:
+			stop = fmt.Errorf("%w after %d attempts: %w", ErrRetriesExhausted, attempt, err)
+		default:
+			delay = policy.Delay(attempt, delay)
+			if opts.MaxElapsed > 0 && elapsed+delay > opts.MaxElapsed {
+				stop = fmt.Errorf("%w after %d attempts in %v: %w", ErrRetriesExhausted, attempt, elapsed.Round(time.Millisecond), err)
+			} else {
+				next = delay
+			}
+		}
+
+		if opts.OnAttempt != nil {
+			opts.OnAttempt(Attempt{Number: attempt, Err: err, Retryable: retryable, Delay: next, Elapsed: elapsed})
+		}
+
+		var zero T
+		if !retryable {
+			return zero, err
+		}
+		if stop != nil {
+			return zero, stop
+		}
+		last = err
+
+		// Cancellation during the wait is reported at the top of the loop
+		timer := time.NewTimer(next)
+		select {
+		case <-timer.C:
+		case <-ctx.Done():
+			timer.Stop()
+		}
+	}
+}
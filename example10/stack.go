@@ -0,0 +1,120 @@
+package main
+
+import (
+	"fmt"
+	"io"
+	"runtime"
+	"strings"
+)
+
+// stackError records where an error was created, or where it was first
+// wrapped if it came from code that records no stack. It is transparent
+// to errors.Is and errors.As.
+type stackError struct {
+	err   error
+	stack []uintptr // Nil if an error further down the chain has the stack
+}
+
+// Errorf is fmt.Errorf, including %w, that also records the caller's
+// stack unless the wrapped error already carries one. Either way the
+// result prints the original stack with %+v.
+func Errorf(format string, args ...any) error {
+	err := fmt.Errorf(format, args...)
+	if hasStack(err) {
+		return &stackError{err: err}
+	}
+	return &stackError{err: err, stack: callers()}
+}
+
+// WithStack records the caller's stack on err, unless err already carries
+// one. It returns nil for a nil error.
+func WithStack(err error) error {
+	if err == nil || hasStack(err) {
+		return err
+	}
+	return &stackError{err: err, stack: callers()}
+}
+
+func (e *stackError) Error() string {
+	return e.err.Error()
+}
+
+func (e *stackError) Unwrap() error {
+	return e.err
+}
+
+// Format prints the message for %v and %s, and the message followed by
+// the stack trace for %+v
+func (e *stackError) Format(s fmt.State, verb rune) {
+	switch {
+	case verb == 'v' && s.Flag('+'):
+		io.WriteString(s, e.Error())
+		io.WriteString(s, "\n")
+		io.WriteString(s, StackTrace(e))
+	case verb == 'q':
+		fmt.Fprintf(s, "%q", e.Error())
+	default:
+		io.WriteString(s, e.Error())
+	}
+}
+
+// hasStack reports whether any error in err's chain carries a stack
+func hasStack(err error) bool {
+	return findStack(err) != nil
+}
+
+// findStack returns the first stack recorded in err's chain, following
+// both Unwrap() error and the Unwrap() []error of errors.Join
+func findStack(err error) []uintptr {
+	if se, ok := err.(*stackError); ok && se.stack != nil {
+		return se.stack
+	}
+	switch u := err.(type) {
+	case interface{ Unwrap() error }:
+		if inner := u.Unwrap(); inner != nil {
+			return findStack(inner)
+		}
+	case interface{ Unwrap() []error }:
+		for _, inner := range u.Unwrap() {
+			if stack := findStack(inner); stack != nil {
+				return stack
+			}
+		}
+	}
+	return nil
+}
+
+// StackTrace returns the stack recorded in err's chain, formatted one
+// frame per line, or "" if there is none. Use it when the error has been
+// wrapped by code that does not print stacks with %+v.
+func StackTrace(err error) string {
+	stack := findStack(err)
+	if stack == nil {
+		return ""
+	}
+	return formatStack(stack)
+}
+
+// callers captures the stack of the code calling Errorf or WithStack
+func callers() []uintptr {
+	pcs := make([]uintptr, 32)
+	n := runtime.Callers(3, pcs) // Skip Callers, callers and the exported helper
+	return pcs[:n]
+}
+
+// formatStack turns program counters into "function\n\tfile:line" lines,
+// leaving out the runtime's own frames
+func formatStack(pcs []uintptr) string {
+	var b strings.Builder
+	frames := runtime.CallersFrames(pcs)
+	for {
+		frame, more := frames.Next()
+		if !strings.HasPrefix(frame.Function, "runtime.") {
+			fmt.Fprintf(&b, "%s\n\t%s:%d\n", frame.Function, frame.File, frame.Line)
+		}
+		if !more {
+			break
+		}
+	}
+	return b.String()
+}
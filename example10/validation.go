@@ -0,0 +1,154 @@
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"reflect"
+	"strings"
+)
+
+// Catalog entries used by the validation framework
+var (
+	ErrRequired   = Define("VALIDATION_REQUIRED", SeverityWarning, "%s is required", false)
+	ErrOutOfRange = Define("VALIDATION_OUT_OF_RANGE", SeverityWarning, "%s must be between %v and %v", false)
+	ErrFutureDate = Define("VALIDATION_FUTURE_DATE", SeverityWarning, "%s must not be in the future", false)
+	ErrNilValue   = Define("VALIDATION_NIL_VALUE", SeverityError, "cannot validate a nil value", false)
+)
+
+// Validatable is a type that can check its own fields
+type Validatable interface {
+	Validate(v *Validator)
+}
+
+// Validator collects every violation found while checking a value,
+// instead of stopping at the first. Fields are named by their path from
+// the top-level value, like Employee.Person.Age.
+type Validator struct {
+	prefix string
+	errs   *ValidationErrors // Shared with nested validators
+}
+
+// Validate checks x and returns all its violations as one
+// ValidationErrors, or nil if there are none. A nil x, or a nil pointer,
+// is an ErrNilValue error rather than a panic.
+func Validate(x Validatable) error {
+	if x == nil {
+		return New(ErrNilValue)
+	}
+	rv := reflect.ValueOf(x)
+	for rv.Kind() == reflect.Pointer {
+		if rv.IsNil() {
+			return New(ErrNilValue)
+		}
+		rv = rv.Elem()
+	}
+	t := rv.Type()
+	v := &Validator{prefix: t.Name(), errs: new(ValidationErrors)}
+	x.Validate(v)
+	return v.Err()
+}
+
+// Err returns the violations collected so far, or nil
+func (v *Validator) Err() error {
+	if len(*v.errs) == 0 {
+		return nil
+	}
+	return *v.errs
+}
+
+// path joins a field name onto the current prefix
+func (v *Validator) path(field string) string {
+	if v.prefix == "" {
+		return field
+	}
+	return v.prefix + "." + field
+}
+
+// Add records a violation of field; err is its catalog error, if any
+func (v *Validator) Add(field, message string, err error) {
+	*v.errs = append(*v.errs, ValidationError{Field: v.path(field), Message: message, Err: err})
+}
+
+// Check records err against field if it is not nil. A ValidationError
+// from a single-value check such as validatePositive keeps its message
+// and catalog error but gets the full path.
+func (v *Validator) Check(field string, err error) {
+	if err == nil {
+		return
+	}
+	var valErr ValidationError
+	if errors.As(err, &valErr) {
+		v.Add(field, valErr.Message, valErr.Err)
+		return
+	}
+	v.Add(field, err.Error(), err)
+}
+
+// Nested checks a field that can validate itself, prefixing its
+// violations with the field name
+func (v *Validator) Nested(field string, x Validatable) {
+	x.Validate(&Validator{prefix: v.path(field), errs: v.errs})
+}
+
+// Required checks that a string is not blank
+func (v *Validator) Required(field, value string) {
+	if strings.TrimSpace(value) == "" {
+		v.Add(field, "is required", New(ErrRequired, v.path(field)))
+	}
+}
+
+// Positive checks that a number is above zero
+func (v *Validator) Positive(field string, value int) {
+	v.Check(field, validatePositive(value, v.path(field)))
+}
+
+// Range checks that a number is within [min, max]
+func (v *Validator) Range(field string, value, min, max int) {
+	if value < min || value > max {
+		v.Add(field, fmt.Sprintf("must be between %d and %d", min, max), New(ErrOutOfRange, v.path(field), min, max))
+	}
+}
+
+// ValidationErrors is every violation found in one value. errors.Is and
+// errors.As look through all of them.
+type ValidationErrors []ValidationError
+
+func (errs ValidationErrors) Error() string {
+	if len(errs) == 1 {
+		return errs[0].Error()
+	}
+	messages := make([]string, len(errs))
+	for i, e := range errs {
+		messages[i] = fmt.Sprintf("%s %s", e.Field, e.Message)
+	}
+	return fmt.Sprintf("%d validation errors: %s", len(errs), strings.Join(messages, "; "))
+}
+
+// Unwrap exposes each violation to errors.Is and errors.As
+func (errs ValidationErrors) Unwrap() []error {
+	result := make([]error, len(errs))
+	for i, e := range errs {
+		result[i] = e
+	}
+	return result
+}
+
+// Fields returns the path of every violation, in the order found
+func (errs ValidationErrors) Fields() []string {
+	fields := make([]string, len(errs))
+	for i, e := range errs {
+		fields[i] = e.Field
+	}
+	return fields
+}
+
+// MarshalJSON writes one violation with its catalog code, so form
+// handlers can return them directly
+func (e ValidationError) MarshalJSON() ([]byte, error) {
+	return json.Marshal(struct {
+		Field   string `json:"field"`
+		Message string `json:"message"`
+		Code    string `json:"code,omitempty"`
+	}{e.Field, e.Message, CodeOf(e.Err)})
+}
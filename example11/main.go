@@ -0,0 +1,173 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+)
+
+// Example 11: Persistent Job Queue
+// Demonstrates a durable worker-pool queue backed by a write-ahead log,
+// with retries, exponential backoff, dead letters and crash recovery
+
+// Function that processes one job, returning an error for failed attempts
+type processFunc func(job Job) (int, error)
+
+// Worker pool pattern, reading from a Queue instead of a channel
+func worker(id int, queue Queue, process processFunc, results chan<- int, wg *sync.WaitGroup) {
+	defer wg.Done()
+
+	for {
+		job, ok := queue.Dequeue()
+		if !ok {
+			return
+		}
+
+		fmt.Printf("Worker %d processing job %d (payload %d)\n", id, job.ID, job.Payload)
+		result, err := process(job)
+		if err != nil {
+			if err := queue.Fail(job, err); err != nil {
+				fmt.Printf("Worker %d could not record failure: %v\n", id, err)
+			}
+			continue
+		}
+
+		if err := queue.Ack(job); err != nil {
+			fmt.Printf("Worker %d could not ack job %d: %v\n", id, job.ID, err)
+			continue
+		}
+		results <- result
+	}
+}
+
+// Function that runs a pool of workers until the queue is drained
+func runPool(queue Queue, numWorkers int, process processFunc) []int {
+	results := make(chan int)
+	var wg sync.WaitGroup
+	for w := 1; w <= numWorkers; w++ {
+		wg.Add(1)
+		go worker(w, queue, process, results, &wg)
+	}
+
+	// Close the results channel when all workers are done
+	go func() {
+		wg.Wait()
+		close(results)
+	}()
+
+	var collected []int
+	for result := range results {
+		collected = append(collected, result)
+	}
+	return collected
+}
+
+// Simulated work: doubles the payload
+func double(job Job) (int, error) {
+	time.Sleep(time.Millisecond * 50)
+	return job.Payload * 2, nil
+}
+
+// Simulated flaky work: payload 3 fails twice before succeeding and
+// payload 5 always fails
+func flakyDouble(job Job) (int, error) {
+	time.Sleep(time.Millisecond * 50)
+	switch {
+	case job.Payload == 3 && job.Attempts < 2:
+		return 0, errors.New("temporary failure")
+	case job.Payload == 5:
+		return 0, errors.New("permanent failure")
+	}
+	return job.Payload * 2, nil
+}
+
+func main() {
+	fmt.Println("Persistent Job Queue in Go:")
+
+	// The in-memory queue behaves like the buffered channel in example 9
+	fmt.Println("\n=== In-Memory Queue ===")
+	memQueue := newMemoryQueue(DefaultRetryPolicy)
+	for j := 1; j <= 5; j++ {
+		memQueue.Enqueue(j)
+	}
+	memQueue.Close()
+	fmt.Println("Results:", runPool(memQueue, 3, double))
+
+	// The durable queue survives a crash
+	dir, err := os.MkdirTemp("", "example11")
+	if err != nil {
+		fmt.Println("Error:", err)
+		return
+	}
+	defer os.RemoveAll(dir)
+
+	fmt.Println("\n=== Durable Queue: First Run ===")
+	queue, _, err := openWALQueue(dir, DefaultRetryPolicy)
+	if err != nil {
+		fmt.Println("Error:", err)
+		return
+	}
+	for j := 1; j <= 6; j++ {
+		job, err := queue.Enqueue(j)
+		if err != nil {
+			fmt.Println("Error:", err)
+			return
+		}
+		fmt.Printf("Enqueued job %d\n", job.ID)
+	}
+
+	// Finish one job, start another, then crash before acking it
+	job, _ := queue.Dequeue()
+	queue.Ack(job)
+	fmt.Printf("Job %d acked\n", job.ID)
+	job, _ = queue.Dequeue()
+	fmt.Printf("Job %d started, then the process crashed\n", job.ID)
+	queue.Abort()
+
+	fmt.Println("\n=== Durable Queue: After Restart ===")
+	queue, recovered, err := openWALQueue(dir, DefaultRetryPolicy)
+	if err != nil {
+		fmt.Println("Error:", err)
+		return
+	}
+	for _, job := range recovered {
+		fmt.Printf("Recovered job %d (payload %d, %d failed attempts)\n", job.ID, job.Payload, job.Attempts)
+	}
+	queue.Close()
+	fmt.Println("Results:", runPool(queue, 3, flakyDouble))
+
+	// Inspect the dead-letter file
+	fmt.Println("\n=== Dead Letters ===")
+	data, err := os.ReadFile(filepath.Join(dir, "dead-letter.jsonl"))
+	if err != nil {
+		fmt.Println("Error:", err)
+		return
+	}
+	fmt.Print(string(data))
+
+	// A third run finds nothing left to do
+	fmt.Println("\n=== Durable Queue: Third Run ===")
+	queue, recovered, err = openWALQueue(dir, DefaultRetryPolicy)
+	if err != nil {
+		fmt.Println("Error:", err)
+		return
+	}
+	fmt.Println("Recovered jobs:", len(recovered))
+
+	// Compaction dropped every record, but IDs carry on from the highest
+	// one ever used, so they never clash with the dead letters
+	job, err = queue.Enqueue(7)
+	if err != nil {
+		fmt.Println("Error:", err)
+		return
+	}
+	fmt.Printf("Enqueued job %d\n", job.ID)
+	job, _ = queue.Dequeue()
+	queue.Ack(job)
+	queue.Close()
+
+	fmt.Println("\nPersistent queue examples completed")
+}
@@ -0,0 +1,195 @@
+package main
+
+import (
+	"fmt"
+	"sync"
+	"time"
+)
+
+// Job is a unit of work handed to the workers
+type Job struct {
+	ID       int `json:"id"`
+	Payload  int `json:"payload"`
+	Attempts int `json:"attempts"` // Number of failed attempts so far
+}
+
+// Queue is the interface the workers consume. The in-memory queue and the
+// durable write-ahead-log queue both implement it, so a worker does not care
+// which one it is reading from.
+type Queue interface {
+	// Enqueue adds a new job to the queue
+	Enqueue(payload int) (Job, error)
+	// Dequeue blocks until a job is ready. It returns false once the queue
+	// has been closed and every job has been acked or dead-lettered.
+	Dequeue() (Job, bool)
+	// Ack marks a job as done
+	Ack(job Job) error
+	// Fail reports a failed attempt; the job is retried or dead-lettered
+	Fail(job Job, cause error) error
+	// Close stops accepting new jobs
+	Close() error
+}
+
+// RetryPolicy controls exponential backoff between attempts
+type RetryPolicy struct {
+	MaxAttempts int
+	BaseDelay   time.Duration
+	MaxDelay    time.Duration
+}
+
+// Backoff returns the delay before the next attempt: BaseDelay doubled for
+// every failed attempt, capped at MaxDelay
+func (p RetryPolicy) Backoff(attempts int) time.Duration {
+	delay := p.BaseDelay
+	for i := 1; i < attempts; i++ {
+		delay *= 2
+		if delay >= p.MaxDelay {
+			return p.MaxDelay
+		}
+	}
+	return delay
+}
+
+// DefaultRetryPolicy is used when no policy is given
+var DefaultRetryPolicy = RetryPolicy{
+	MaxAttempts: 3,
+	BaseDelay:   50 * time.Millisecond,
+	MaxDelay:    time.Second,
+}
+
+// memoryQueue keeps jobs in memory only; everything is lost on exit
+type memoryQueue struct {
+	mu       sync.Mutex
+	cond     *sync.Cond
+	policy   RetryPolicy
+	ready    []Job
+	delayed  int // Jobs waiting for their backoff timer
+	inFlight int // Jobs handed to a worker but not yet acked or failed
+	nextID   int
+	closed   bool
+	aborted  bool
+	dead     []Job
+}
+
+// Constructor for the in-memory queue
+func newMemoryQueue(policy RetryPolicy) *memoryQueue {
+	q := &memoryQueue{policy: policy, nextID: 1}
+	q.cond = sync.NewCond(&q.mu)
+	return q
+}
+
+func (q *memoryQueue) Enqueue(payload int) (Job, error) {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	if q.closed {
+		return Job{}, fmt.Errorf("enqueue job: queue is closed")
+	}
+	job := Job{ID: q.nextID, Payload: payload}
+	q.nextID++
+	q.pushLocked(job)
+	return job, nil
+}
+
+// pushLocked makes a job ready for the workers; q.mu must be held
+func (q *memoryQueue) pushLocked(job Job) {
+	q.ready = append(q.ready, job)
+	q.cond.Signal()
+}
+
+func (q *memoryQueue) Dequeue() (Job, bool) {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	for len(q.ready) == 0 && !q.drainedLocked() {
+		q.cond.Wait()
+	}
+	if len(q.ready) == 0 || q.aborted {
+		return Job{}, false
+	}
+
+	job := q.ready[0]
+	q.ready = q.ready[1:]
+	q.inFlight++
+	return job, true
+}
+
+// drainedLocked reports whether workers should stop; q.mu must be held
+func (q *memoryQueue) drainedLocked() bool {
+	if q.aborted {
+		return true
+	}
+	return q.closed && len(q.ready) == 0 && q.delayed == 0 && q.inFlight == 0
+}
+
+func (q *memoryQueue) Ack(job Job) error {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	q.inFlight--
+	q.cond.Broadcast() // Waiting workers may now be able to exit
+	return nil
+}
+
+func (q *memoryQueue) Fail(job Job, cause error) error {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	q.inFlight--
+	job.Attempts++
+	if job.Attempts >= q.policy.MaxAttempts {
+		q.dead = append(q.dead, job)
+		q.cond.Broadcast()
+		fmt.Printf("Job %d moved to dead letters after %d attempts: %v\n", job.ID, job.Attempts, cause)
+		return nil
+	}
+
+	delay := q.retryLocked(job)
+	fmt.Printf("Job %d will be retried in %v (attempt %d failed)\n", job.ID, delay, job.Attempts)
+	return nil
+}
+
+// retryLocked puts a job back on the queue after its backoff delay;
+// q.mu must be held
+func (q *memoryQueue) retryLocked(job Job) time.Duration {
+	delay := q.policy.Backoff(job.Attempts)
+	q.delayed++
+	time.AfterFunc(delay, func() {
+		q.mu.Lock()
+		defer q.mu.Unlock()
+
+		q.delayed--
+		if q.aborted {
+			return
+		}
+		q.pushLocked(job)
+		q.cond.Broadcast()
+	})
+	return delay
+}
+
+// DeadLetters returns the jobs that ran out of attempts
+func (q *memoryQueue) DeadLetters() []Job {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	return append([]Job(nil), q.dead...)
+}
+
+func (q *memoryQueue) Close() error {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	q.closed = true
+	q.cond.Broadcast()
+	return nil
+}
+
+// abort stops the queue immediately, leaving pending jobs where they are
+func (q *memoryQueue) abort() {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	q.closed = true
+	q.aborted = true
+	q.cond.Broadcast()
+}
@@ -0,0 +1,292 @@
+package main
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"time"
+)
+
+// Operations recorded in the write-ahead log
+const (
+	opEnqueue = "enqueue"
+	opAck     = "ack"
+	opRetry   = "retry"
+	opDead    = "dead"
+	// Written first by compaction: the job ID is the highest ever used, so
+	// IDs stay unique after the records that used them are dropped
+	opHighWater = "high-water"
+)
+
+// walRecord is one line of the write-ahead log
+type walRecord struct {
+	Op  string `json:"op"`
+	Job Job    `json:"job"`
+}
+
+// deadLetter is one line of the dead-letter file
+type deadLetter struct {
+	Job    Job       `json:"job"`
+	Error  string    `json:"error"`
+	Failed time.Time `json:"failed"`
+}
+
+// walQueue is a durable queue. Every state change is appended to a log file
+// and synced to disk before it takes effect, so a restarted process can
+// rebuild the queue from the log.
+type walQueue struct {
+	*memoryQueue
+	log         *os.File
+	deadLetters *os.File
+}
+
+// openWALQueue opens (or creates) a durable queue in dir. Jobs that were
+// enqueued but never acked or dead-lettered are recovered and returned.
+func openWALQueue(dir string, policy RetryPolicy) (*walQueue, []Job, error) {
+	logPath := filepath.Join(dir, "queue.wal")
+
+	pending, nextID, err := replayWAL(logPath)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	// Compact the log so it only holds the jobs that are still pending
+	if err := rewriteWAL(logPath, pending, nextID); err != nil {
+		return nil, nil, err
+	}
+
+	log, err := os.OpenFile(logPath, os.O_WRONLY|os.O_APPEND, 0o644)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to open write-ahead log: %w", err)
+	}
+
+	deadPath := filepath.Join(dir, "dead-letter.jsonl")
+	deadLetters, err := os.OpenFile(deadPath, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0o644)
+	if err != nil {
+		log.Close()
+		return nil, nil, fmt.Errorf("failed to open dead-letter file: %w", err)
+	}
+
+	q := &walQueue{
+		memoryQueue: newMemoryQueue(policy),
+		log:         log,
+		deadLetters: deadLetters,
+	}
+	q.nextID = nextID
+
+	// Recovered jobs are ready straight away; their backoff timers were
+	// lost with the old process
+	for _, job := range pending {
+		q.ready = append(q.ready, job)
+	}
+	return q, pending, nil
+}
+
+// replayWAL reads the log and returns the jobs that are still pending along
+// with the next free job ID
+func replayWAL(path string) ([]Job, int, error) {
+	file, err := os.Open(path)
+	if os.IsNotExist(err) {
+		return nil, 1, nil
+	}
+	if err != nil {
+		return nil, 0, fmt.Errorf("failed to open write-ahead log: %w", err)
+	}
+	defer file.Close()
+
+	pending := make(map[int]Job)
+	nextID := 1
+
+	scanner := bufio.NewScanner(file)
+	for scanner.Scan() {
+		var rec walRecord
+		if err := json.Unmarshal(scanner.Bytes(), &rec); err != nil {
+			// A torn record can only be the last one written before a
+			// crash, and it was never acknowledged to anyone: stop here
+			fmt.Println("Ignoring incomplete log record:", err)
+			break
+		}
+
+		switch rec.Op {
+		case opEnqueue, opRetry:
+			pending[rec.Job.ID] = rec.Job
+		case opAck, opDead:
+			delete(pending, rec.Job.ID)
+		case opHighWater:
+			// Only raises nextID, below
+		default:
+			return nil, 0, fmt.Errorf("unknown log operation %q", rec.Op)
+		}
+
+		if rec.Job.ID >= nextID {
+			nextID = rec.Job.ID + 1
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, 0, fmt.Errorf("failed to read write-ahead log: %w", err)
+	}
+
+	jobs := make([]Job, 0, len(pending))
+	for _, job := range pending {
+		jobs = append(jobs, job)
+	}
+	sort.Slice(jobs, func(i, j int) bool { return jobs[i].ID < jobs[j].ID })
+	return jobs, nextID, nil
+}
+
+// rewriteWAL atomically replaces the log with a high-water record, so job
+// IDs are never reused, and one enqueue record per job
+func rewriteWAL(path string, jobs []Job, nextID int) error {
+	tmpPath := path + ".tmp"
+	tmp, err := os.Create(tmpPath)
+	if err != nil {
+		return fmt.Errorf("failed to create compacted log: %w", err)
+	}
+	defer os.Remove(tmpPath) // No-op once the rename has succeeded
+
+	enc := json.NewEncoder(tmp)
+	records := []walRecord{{Op: opHighWater, Job: Job{ID: nextID - 1}}}
+	for _, job := range jobs {
+		records = append(records, walRecord{Op: opEnqueue, Job: job})
+	}
+	for _, rec := range records {
+		if err := enc.Encode(rec); err != nil {
+			tmp.Close()
+			return fmt.Errorf("failed to write compacted log: %w", err)
+		}
+	}
+	if err := tmp.Sync(); err != nil {
+		tmp.Close()
+		return fmt.Errorf("failed to sync compacted log: %w", err)
+	}
+	if err := tmp.Close(); err != nil {
+		return fmt.Errorf("failed to close compacted log: %w", err)
+	}
+	return os.Rename(tmpPath, path)
+}
+
+// appendLocked writes a record to the log and syncs it; q.mu must be held
+func (q *walQueue) appendLocked(op string, job Job) error {
+	if q.aborted {
+		return fmt.Errorf("%s job %d: queue was aborted", op, job.ID)
+	}
+
+	data, err := json.Marshal(walRecord{Op: op, Job: job})
+	if err != nil {
+		return fmt.Errorf("failed to encode log record: %w", err)
+	}
+	data = append(data, '\n')
+	if _, err := q.log.Write(data); err != nil {
+		return fmt.Errorf("failed to append to log: %w", err)
+	}
+	return q.log.Sync()
+}
+
+func (q *walQueue) Enqueue(payload int) (Job, error) {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	if q.closed {
+		return Job{}, fmt.Errorf("enqueue job: queue is closed")
+	}
+	job := Job{ID: q.nextID, Payload: payload}
+	if err := q.appendLocked(opEnqueue, job); err != nil {
+		return Job{}, err
+	}
+	q.nextID++
+	q.pushLocked(job)
+	return job, nil
+}
+
+func (q *walQueue) Ack(job Job) error {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	// The job leaves this process either way: if the ack cannot be
+	// written it stays pending in the log and is recovered on restart
+	q.inFlight--
+	q.cond.Broadcast()
+	if err := q.appendLocked(opAck, job); err != nil {
+		return err
+	}
+	return q.closeFilesIfDrainedLocked()
+}
+
+func (q *walQueue) Fail(job Job, cause error) error {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	q.inFlight--
+	q.cond.Broadcast()
+
+	job.Attempts++
+	if job.Attempts < q.policy.MaxAttempts {
+		if err := q.appendLocked(opRetry, job); err != nil {
+			return err
+		}
+		delay := q.retryLocked(job)
+		fmt.Printf("Job %d will be retried in %v (attempt %d failed)\n", job.ID, delay, job.Attempts)
+		return nil
+	}
+
+	// Write the dead letter first: if we crash in between, the job is
+	// still pending in the log and will be retried rather than lost
+	if err := q.writeDeadLetterLocked(job, cause); err != nil {
+		return err
+	}
+	if err := q.appendLocked(opDead, job); err != nil {
+		return err
+	}
+	q.dead = append(q.dead, job)
+	fmt.Printf("Job %d moved to dead letters after %d attempts: %v\n", job.ID, job.Attempts, cause)
+	return q.closeFilesIfDrainedLocked()
+}
+
+// writeDeadLetterLocked appends a failed job to the dead-letter file
+func (q *walQueue) writeDeadLetterLocked(job Job, cause error) error {
+	data, err := json.Marshal(deadLetter{Job: job, Error: cause.Error(), Failed: time.Now()})
+	if err != nil {
+		return fmt.Errorf("failed to encode dead letter: %w", err)
+	}
+	data = append(data, '\n')
+	if _, err := q.deadLetters.Write(data); err != nil {
+		return fmt.Errorf("failed to write dead letter: %w", err)
+	}
+	return q.deadLetters.Sync()
+}
+
+func (q *walQueue) Close() error {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	q.closed = true
+	q.cond.Broadcast()
+	return q.closeFilesIfDrainedLocked()
+}
+
+// closeFilesIfDrainedLocked releases the files once the queue is closed and
+// no job can change state any more; q.mu must be held
+func (q *walQueue) closeFilesIfDrainedLocked() error {
+	if !q.drainedLocked() || q.log == nil {
+		return nil
+	}
+	err := q.log.Close()
+	if dlErr := q.deadLetters.Close(); err == nil {
+		err = dlErr
+	}
+	q.log, q.deadLetters = nil, nil
+	return err
+}
+
+// Abort simulates a crash: the files are closed without recording anything
+// else, so jobs that were not acked stay pending in the log
+func (q *walQueue) Abort() error {
+	q.abort()
+
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	return q.closeFilesIfDrainedLocked()
+}
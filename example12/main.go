@@ -0,0 +1,156 @@
+package main
+
+import (
+	"fmt"
+	"sync"
+	"time"
+)
+
+// Example 12: Priority Scheduling
+// Demonstrates a worker pool that runs jobs by priority, skips jobs whose
+// deadline has passed and uses aging to stop low-priority jobs starving
+
+// Result of a job, or the reason it was skipped
+type Result struct {
+	JobID   int
+	Value   int
+	Skipped bool
+}
+
+// Worker pool pattern, reading from the scheduler instead of a channel
+func worker(id int, scheduler *Scheduler, results chan<- Result, wg *sync.WaitGroup) {
+	defer wg.Done()
+
+	for {
+		job, ok := scheduler.Next()
+		if !ok {
+			return
+		}
+		fmt.Printf("Worker %d processing job %d (priority %d)\n", id, job.ID, job.Priority)
+		time.Sleep(time.Millisecond * 50) // Simulate work
+		results <- Result{JobID: job.ID, Value: job.Value * 2}
+	}
+}
+
+// Function that runs the jobs through a pool and prints the outcome
+func runJobs(config SchedulerConfig, jobs []Job, numWorkers int) {
+	results := make(chan Result, len(jobs))
+	config.OnSkip = func(job Job) {
+		fmt.Printf("Skipping job %d: deadline passed %v ago\n", job.ID, time.Since(job.Deadline).Round(time.Millisecond))
+		results <- Result{JobID: job.ID, Skipped: true}
+	}
+	scheduler := NewScheduler(config)
+
+	// Queue everything before starting the workers so the order is visible
+	for _, job := range jobs {
+		if err := scheduler.Submit(job); err != nil {
+			fmt.Println("Error:", err)
+		}
+	}
+	scheduler.Close()
+
+	var wg sync.WaitGroup
+	for w := 1; w <= numWorkers; w++ {
+		wg.Add(1)
+		go worker(w, scheduler, results, &wg)
+	}
+
+	// Close the results channel when all workers are done
+	go func() {
+		wg.Wait()
+		close(results)
+	}()
+
+	done, skipped := 0, 0
+	for result := range results {
+		if result.Skipped {
+			skipped++
+		} else {
+			done++
+		}
+	}
+	fmt.Printf("Completed: %d, skipped: %d\n", done, skipped)
+}
+
+// Function that feeds a steady stream of priority 2 jobs to a single worker
+// and reports at which position the lone priority 0 job was run
+func agingDemo(config SchedulerConfig) int {
+	results := make(chan Result, 16)
+	scheduler := NewScheduler(config)
+	for _, job := range []Job{{ID: 1, Priority: 0}, {ID: 2, Priority: 2}} {
+		if err := scheduler.Submit(job); err != nil {
+			fmt.Println("Error:", err)
+		}
+	}
+
+	var wg sync.WaitGroup
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		for {
+			job, ok := scheduler.Next()
+			if !ok {
+				return
+			}
+			time.Sleep(time.Millisecond * 30) // Simulate work
+			results <- Result{JobID: job.ID}
+		}
+	}()
+
+	for j := 3; j <= 10; j++ {
+		time.Sleep(time.Millisecond * 25)
+		if err := scheduler.Submit(Job{ID: j, Priority: 2}); err != nil {
+			fmt.Println("Error:", err)
+		}
+	}
+	scheduler.Close()
+	wg.Wait()
+	close(results)
+
+	position := 0
+	for result := range results {
+		position++
+		if result.JobID == 1 {
+			break
+		}
+	}
+	return position
+}
+
+func main() {
+	fmt.Println("Priority Scheduling in Go:")
+
+	// Highest priority first, FIFO among equal priorities
+	fmt.Println("\n=== Priority Order ===")
+	runJobs(SchedulerConfig{}, []Job{
+		{ID: 1, Value: 1, Priority: 1},
+		{ID: 2, Value: 2, Priority: 5},
+		{ID: 3, Value: 3, Priority: 3},
+		{ID: 4, Value: 4, Priority: 5},
+		{ID: 5, Value: 5, Priority: 1},
+	}, 1)
+
+	// A job that waits past its deadline is skipped, not run late
+	fmt.Println("\n=== Deadlines ===")
+	now := time.Now()
+	runJobs(SchedulerConfig{}, []Job{
+		{ID: 1, Value: 1, Priority: 9},
+		{ID: 2, Value: 2, Priority: 9},
+		{ID: 3, Value: 3, Priority: 1, Deadline: now.Add(time.Millisecond * 30)},
+		{ID: 4, Value: 4, Priority: 1, Deadline: now.Add(time.Second)},
+	}, 1)
+
+	// Without aging, a stream of high-priority jobs keeps job 1 waiting
+	// until the very end. With aging it gains a level every 20ms.
+	fmt.Println("\n=== Aging ===")
+	fmt.Println("Job 1 ran at position", agingDemo(SchedulerConfig{}), "without aging")
+	fmt.Println("Job 1 ran at position", agingDemo(SchedulerConfig{AgingInterval: time.Millisecond * 20}), "with aging")
+
+	// A closed scheduler refuses new jobs
+	fmt.Println("\n=== Closed Scheduler ===")
+	scheduler := NewScheduler(SchedulerConfig{})
+	scheduler.Close()
+	fmt.Println("Submit after close:", scheduler.Submit(Job{ID: 1}))
+
+	fmt.Println("\nPriority scheduling examples completed")
+}
@@ -0,0 +1,152 @@
+package main
+
+import (
+	"container/heap"
+	"errors"
+	"sync"
+	"time"
+)
+
+// ErrSchedulerClosed is returned by Submit after Close
+var ErrSchedulerClosed = errors.New("scheduler closed")
+
+// Job with a priority and an optional deadline
+type Job struct {
+	ID       int
+	Value    int
+	Priority int       // Higher runs first
+	Deadline time.Time // Zero means no deadline
+	enqueued time.Time
+	seq      int // Submission order, used to keep equal jobs FIFO
+}
+
+// Expired reports whether the job's deadline has passed
+func (j Job) Expired(now time.Time) bool {
+	return !j.Deadline.IsZero() && now.After(j.Deadline)
+}
+
+// SchedulerConfig controls how jobs are ordered
+type SchedulerConfig struct {
+	// AgingInterval raises a waiting job's priority by one for every
+	// interval it spends in the queue, so low-priority jobs cannot starve.
+	// Zero disables aging.
+	AgingInterval time.Duration
+
+	// OnSkip is called for every job dropped because its deadline passed
+	OnSkip func(job Job)
+}
+
+// jobHeap orders jobs by effective priority
+type jobHeap struct {
+	jobs  []Job
+	aging time.Duration
+}
+
+func (h *jobHeap) Len() int { return len(h.jobs) }
+
+// Less compares effective priorities. With aging, a job's effective
+// priority is Priority + waited/AgingInterval. Every job ages at the same
+// rate, so the difference between two jobs never changes and comparing
+// Priority*AgingInterval - enqueued gives the same order at any moment.
+func (h *jobHeap) Less(i, j int) bool {
+	a, b := h.jobs[i], h.jobs[j]
+	if h.aging > 0 {
+		ka := time.Duration(a.Priority)*h.aging - time.Duration(a.enqueued.UnixNano())
+		kb := time.Duration(b.Priority)*h.aging - time.Duration(b.enqueued.UnixNano())
+		if ka != kb {
+			return ka > kb
+		}
+	} else if a.Priority != b.Priority {
+		return a.Priority > b.Priority
+	}
+	return a.seq < b.seq
+}
+
+func (h *jobHeap) Swap(i, j int) { h.jobs[i], h.jobs[j] = h.jobs[j], h.jobs[i] }
+
+func (h *jobHeap) Push(x any) { h.jobs = append(h.jobs, x.(Job)) }
+
+func (h *jobHeap) Pop() any {
+	last := h.jobs[len(h.jobs)-1]
+	h.jobs = h.jobs[:len(h.jobs)-1]
+	return last
+}
+
+// Scheduler hands out jobs in priority order instead of FIFO
+type Scheduler struct {
+	mu     sync.Mutex
+	cond   *sync.Cond
+	config SchedulerConfig
+	queue  *jobHeap
+	seq    int
+	closed bool
+}
+
+// Constructor for the scheduler
+func NewScheduler(config SchedulerConfig) *Scheduler {
+	s := &Scheduler{
+		config: config,
+		queue:  &jobHeap{aging: config.AgingInterval},
+	}
+	s.cond = sync.NewCond(&s.mu)
+	return s
+}
+
+// Submit adds a job to the scheduler. It returns ErrSchedulerClosed once
+// Close has been called.
+func (s *Scheduler) Submit(job Job) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.closed {
+		return ErrSchedulerClosed
+	}
+	job.enqueued = time.Now()
+	job.seq = s.seq
+	s.seq++
+	heap.Push(s.queue, job)
+	s.cond.Signal()
+	return nil
+}
+
+// Next blocks until a job is available and returns the one with the highest
+// effective priority. Expired jobs are skipped and reported through OnSkip.
+// It returns false once the scheduler is closed and empty.
+func (s *Scheduler) Next() (Job, bool) {
+	for {
+		s.mu.Lock()
+		for s.queue.Len() == 0 && !s.closed {
+			s.cond.Wait()
+		}
+		if s.queue.Len() == 0 {
+			s.mu.Unlock()
+			return Job{}, false
+		}
+		job := heap.Pop(s.queue).(Job)
+		s.mu.Unlock()
+
+		if !job.Expired(time.Now()) {
+			return job, true
+		}
+		// Report outside the lock so the callback may call back in
+		if s.config.OnSkip != nil {
+			s.config.OnSkip(job)
+		}
+	}
+}
+
+// Len returns the number of waiting jobs
+func (s *Scheduler) Len() int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.queue.Len()
+}
+
+// Close stops new submissions; queued jobs are still handed out
+func (s *Scheduler) Close() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.closed = true
+	s.cond.Broadcast()
+}
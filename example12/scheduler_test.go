@@ -0,0 +1,118 @@
+package main
+
+import (
+	"container/heap"
+	"errors"
+	"slices"
+	"testing"
+	"time"
+)
+
+// Function that submits jobs, failing the test if any is refused
+func submitAll(t *testing.T, s *Scheduler, jobs ...Job) {
+	t.Helper()
+	for _, job := range jobs {
+		if err := s.Submit(job); err != nil {
+			t.Fatalf("submit job %d: %v", job.ID, err)
+		}
+	}
+}
+
+// Function that takes every job from a closed scheduler
+func drainIDs(s *Scheduler) []int {
+	var ids []int
+	for {
+		job, ok := s.Next()
+		if !ok {
+			return ids
+		}
+		ids = append(ids, job.ID)
+	}
+}
+
+func TestPriorityOrder(t *testing.T) {
+	s := NewScheduler(SchedulerConfig{})
+	for i, priority := range []int{1, 5, 3, 5, 1, 9} {
+		submitAll(t, s, Job{ID: i + 1, Priority: priority})
+	}
+	s.Close()
+
+	// Highest first; equal priorities keep submission order
+	want := []int{6, 2, 4, 3, 1, 5}
+	if got := drainIDs(s); !slices.Equal(got, want) {
+		t.Errorf("order = %v, want %v", got, want)
+	}
+}
+
+func TestExpiredJobsSkipped(t *testing.T) {
+	var skipped []int
+	s := NewScheduler(SchedulerConfig{OnSkip: func(job Job) { skipped = append(skipped, job.ID) }})
+	submitAll(t, s,
+		Job{ID: 1, Priority: 1},
+		Job{ID: 2, Priority: 2, Deadline: time.Now().Add(-time.Second)},
+		Job{ID: 3, Priority: 3, Deadline: time.Now().Add(time.Hour)},
+	)
+	s.Close()
+
+	if got := drainIDs(s); !slices.Equal(got, []int{3, 1}) {
+		t.Errorf("ran %v, want [3 1]", got)
+	}
+	if !slices.Equal(skipped, []int{2}) {
+		t.Errorf("skipped %v, want [2]", skipped)
+	}
+}
+
+func TestSubmitAfterClose(t *testing.T) {
+	s := NewScheduler(SchedulerConfig{})
+	submitAll(t, s, Job{ID: 1})
+	s.Close()
+
+	if err := s.Submit(Job{ID: 2}); !errors.Is(err, ErrSchedulerClosed) {
+		t.Errorf("submit after close: %v, want ErrSchedulerClosed", err)
+	}
+	// Jobs queued before Close still run
+	if got := drainIDs(s); !slices.Equal(got, []int{1}) {
+		t.Errorf("ran %v, want [1]", got)
+	}
+}
+
+func TestAging(t *testing.T) {
+	base := time.Date(2026, time.January, 5, 9, 0, 0, 0, time.UTC)
+	push := func(h *jobHeap, id, priority int, waited time.Duration) {
+		heap.Push(h, Job{ID: id, Priority: priority, enqueued: base.Add(-waited), seq: h.Len()})
+	}
+	pop := func(h *jobHeap) []int {
+		var ids []int
+		for h.Len() > 0 {
+			ids = append(ids, heap.Pop(h).(Job).ID)
+		}
+		return ids
+	}
+
+	// Without aging an old low-priority job waits behind every newer
+	// high-priority one
+	h := &jobHeap{}
+	push(h, 1, 1, time.Minute)
+	push(h, 2, 5, 0)
+	push(h, 3, 3, 0)
+	if got := pop(h); !slices.Equal(got, []int{2, 3, 1}) {
+		t.Errorf("without aging: %v, want [2 3 1]", got)
+	}
+
+	// With one level per second, a minute of waiting outranks priority 5
+	h = &jobHeap{aging: time.Second}
+	push(h, 1, 1, time.Minute)
+	push(h, 2, 5, 0)
+	push(h, 3, 3, 0)
+	if got := pop(h); !slices.Equal(got, []int{1, 2, 3}) {
+		t.Errorf("with aging: %v, want [1 2 3]", got)
+	}
+
+	// Waiting exactly the gap ties, and ties go to the earlier submission
+	h = &jobHeap{aging: time.Second}
+	push(h, 1, 3, 2*time.Second)
+	push(h, 2, 5, 0)
+	if got := pop(h); !slices.Equal(got, []int{1, 2}) {
+		t.Errorf("tie: %v, want [1 2]", got)
+	}
+}
@@ -0,0 +1,86 @@
+package main
+
+import (
+	"fmt"
+	"time"
+)
+
+// Example 13: Autoscaling Worker Pool
+// Demonstrates a worker pool that grows under load and shrinks when idle,
+// instead of starting a fixed number of workers
+
+// Simulated work: doubles the value
+func double(value int) int {
+	time.Sleep(time.Millisecond * 50)
+	return value * 2
+}
+
+func main() {
+	fmt.Println("Autoscaling Worker Pool in Go:")
+
+	pool, err := NewPool(PoolConfig{
+		MinWorkers:        1,
+		MaxWorkers:        6,
+		QueueSize:         100,
+		QueueHighWater:    5,
+		LatencyTarget:     time.Millisecond * 200,
+		ScaleUpCooldown:   time.Millisecond * 50,
+		ScaleDownCooldown: time.Millisecond * 200,
+		CheckInterval:     time.Millisecond * 25,
+	}, double)
+	if err != nil {
+		fmt.Println("Error:", err)
+		return
+	}
+
+	// Collect results in the background
+	collected := make(chan int)
+	go func() {
+		count := 0
+		for range pool.Results() {
+			count++
+		}
+		collected <- count
+	}()
+
+	// A burst of work makes the pool grow
+	fmt.Println("\n=== Burst of Jobs ===")
+	for j := 1; j <= 60; j++ {
+		if err := pool.Submit(j); err != nil {
+			fmt.Println("Error:", err)
+		}
+	}
+	for i := 0; i < 8; i++ {
+		stats := pool.Stats()
+		fmt.Printf("Workers: %d, queued: %d, latency: %v, processed: %d\n",
+			stats.Workers, stats.QueueDepth, stats.Latency.Round(time.Millisecond), stats.Processed)
+		time.Sleep(time.Millisecond * 100)
+	}
+
+	// With nothing to do the pool shrinks back to its minimum
+	fmt.Println("\n=== Idle ===")
+	time.Sleep(time.Second * 2)
+	fmt.Println("Workers after idle period:", pool.Size())
+
+	// Closing drains the queue and stops every worker
+	fmt.Println("\n=== Shutdown ===")
+	if err := pool.Submit(100); err != nil {
+		fmt.Println("Error:", err)
+	}
+	pool.Close()
+	fmt.Println("Results collected:", <-collected)
+	fmt.Println("Submit after close:", pool.Submit(101))
+
+	fmt.Println("\n=== Scaling Decisions ===")
+	for _, decision := range pool.Decisions() {
+		fmt.Println(decision)
+	}
+
+	// Limits that cannot work are rejected up front
+	fmt.Println("\n=== Invalid Config ===")
+	if _, err := NewPool(PoolConfig{MinWorkers: 4, MaxWorkers: 2}, double); err != nil {
+		fmt.Println("Error:", err)
+	}
+
+	fmt.Println("\nAutoscaling examples completed")
+}
@@ -0,0 +1,341 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+)
+
+// PoolConfig sets the limits and thresholds for autoscaling
+type PoolConfig struct {
+	MinWorkers int
+	MaxWorkers int
+	QueueSize  int
+
+	// Scale up when more jobs than this are waiting, or when the average
+	// job latency (queue wait plus processing) exceeds LatencyTarget
+	QueueHighWater int
+	LatencyTarget  time.Duration
+
+	// Minimum time between two scaling decisions in each direction
+	ScaleUpCooldown   time.Duration
+	ScaleDownCooldown time.Duration
+
+	// How often the controller looks at the queue
+	CheckInterval time.Duration
+}
+
+// DefaultCheckInterval is used when PoolConfig.CheckInterval is not set
+const DefaultCheckInterval = 100 * time.Millisecond
+
+func (c PoolConfig) withDefaults() PoolConfig {
+	if c.CheckInterval <= 0 {
+		c.CheckInterval = DefaultCheckInterval
+	}
+	return c
+}
+
+// validate rejects limits the pool cannot work within
+func (c PoolConfig) validate() error {
+	switch {
+	case c.MaxWorkers <= 0:
+		return fmt.Errorf("MaxWorkers is %d, must be positive", c.MaxWorkers)
+	case c.MinWorkers < 0:
+		return fmt.Errorf("MinWorkers is %d, must not be negative", c.MinWorkers)
+	case c.MinWorkers > c.MaxWorkers:
+		return fmt.Errorf("MinWorkers %d is above MaxWorkers %d", c.MinWorkers, c.MaxWorkers)
+	case c.QueueSize < 0:
+		return fmt.Errorf("QueueSize is %d, must not be negative", c.QueueSize)
+	}
+	return nil
+}
+
+// Decision records one scaling action for observability
+type Decision struct {
+	Time       time.Time
+	From, To   int
+	QueueDepth int
+	Latency    time.Duration
+	Reason     string
+}
+
+func (d Decision) String() string {
+	return fmt.Sprintf("%s %d -> %d workers (queue %d, latency %v): %s",
+		d.Time.Format("15:04:05.000"), d.From, d.To, d.QueueDepth, d.Latency.Round(time.Millisecond), d.Reason)
+}
+
+// Stats is a snapshot of the pool's state
+type Stats struct {
+	Workers    int
+	QueueDepth int
+	Latency    time.Duration // Moving average of recent job latencies
+	Processed  int
+}
+
+// ErrPoolClosed is returned by Submit after Close
+var ErrPoolClosed = errors.New("pool closed")
+
+// queuedJob remembers when a job was submitted so latency can be measured
+type queuedJob struct {
+	value     int
+	submitted time.Time
+}
+
+// Pool is a worker pool that resizes itself between MinWorkers and
+// MaxWorkers based on queue depth and job latency
+type Pool struct {
+	config  PoolConfig
+	process func(int) int
+	jobs    chan queuedJob
+	results chan int
+	done    chan struct{} // Closed to stop the controller
+
+	// Submit holds a read lock while sending, so Close can wait for
+	// sends in flight before closing the jobs channel
+	submitMu sync.RWMutex
+	closed   bool
+
+	mu        sync.Mutex
+	quits     []chan struct{} // One quit channel per running worker
+	nextID    int
+	latency   time.Duration // Moving average, updated by the controller
+	window    time.Duration // Sum of latencies since the last check
+	samples   int           // Number of latencies in window
+	processed int
+	decisions []Decision
+	lastUp    time.Time
+	lastDown  time.Time
+
+	workerWg     sync.WaitGroup
+	controllerWg sync.WaitGroup
+}
+
+// Constructor for the pool; starts MinWorkers workers and the controller.
+// A CheckInterval that is not set gets DefaultCheckInterval.
+func NewPool(config PoolConfig, process func(int) int) (*Pool, error) {
+	config = config.withDefaults()
+	if err := config.validate(); err != nil {
+		return nil, fmt.Errorf("invalid pool config: %w", err)
+	}
+	p := &Pool{
+		config:  config,
+		process: process,
+		jobs:    make(chan queuedJob, config.QueueSize),
+		results: make(chan int, config.QueueSize),
+		done:    make(chan struct{}),
+	}
+
+	p.mu.Lock()
+	for i := 0; i < config.MinWorkers; i++ {
+		p.startWorkerLocked()
+	}
+	p.mu.Unlock()
+
+	p.controllerWg.Add(1)
+	go p.controller()
+	return p, nil
+}
+
+// Submit queues a job, blocking while the queue is full. It returns
+// ErrPoolClosed once Close has been called.
+func (p *Pool) Submit(value int) error {
+	p.submitMu.RLock()
+	defer p.submitMu.RUnlock()
+	if p.closed {
+		return ErrPoolClosed
+	}
+	p.jobs <- queuedJob{value: value, submitted: time.Now()}
+	return nil
+}
+
+// Results returns the channel results are delivered on; it is closed by Close
+func (p *Pool) Results() <-chan int {
+	return p.results
+}
+
+// Size returns the current number of workers
+func (p *Pool) Size() int {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	return len(p.quits)
+}
+
+// Stats returns a snapshot of the pool's state
+func (p *Pool) Stats() Stats {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	return Stats{
+		Workers:    len(p.quits),
+		QueueDepth: len(p.jobs),
+		Latency:    p.latency,
+		Processed:  p.processed,
+	}
+}
+
+// Decisions returns every scaling decision made so far
+func (p *Pool) Decisions() []Decision {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	return append([]Decision(nil), p.decisions...)
+}
+
+// Close stops accepting jobs, lets the workers drain the queue and closes
+// the results channel once they have all exited
+func (p *Pool) Close() {
+	// Blocked submits still finish, as the controller keeps the pool
+	// running until they have
+	p.submitMu.Lock()
+	p.closed = true
+	p.submitMu.Unlock()
+
+	close(p.done)
+	p.controllerWg.Wait() // No scaling while shutting down
+
+	close(p.jobs)
+	p.mu.Lock()
+	if len(p.quits) == 0 && len(p.jobs) > 0 {
+		p.startWorkerLocked() // Someone has to drain the queue
+	}
+	p.mu.Unlock()
+	p.workerWg.Wait()
+	close(p.results)
+}
+
+// startWorkerLocked launches one more worker; p.mu must be held
+func (p *Pool) startWorkerLocked() {
+	p.nextID++
+	quit := make(chan struct{})
+	p.quits = append(p.quits, quit)
+
+	p.workerWg.Add(1)
+	go p.worker(p.nextID, quit)
+}
+
+// stopWorkerLocked asks the newest worker to exit; p.mu must be held.
+// The worker finishes the job it is running before it sees the request.
+func (p *Pool) stopWorkerLocked() {
+	last := len(p.quits) - 1
+	close(p.quits[last])
+	p.quits = p.quits[:last]
+}
+
+// Worker loop: runs jobs until the queue is closed or it is told to quit
+func (p *Pool) worker(id int, quit <-chan struct{}) {
+	defer p.workerWg.Done()
+
+	for {
+		select {
+		case <-quit:
+			fmt.Printf("Worker %d stopped\n", id)
+			return
+		case job, ok := <-p.jobs:
+			if !ok {
+				return
+			}
+			result := p.process(job.value)
+			p.recordLatency(time.Since(job.submitted))
+			p.results <- result
+		}
+	}
+}
+
+// recordLatency adds a sample to the current window
+func (p *Pool) recordLatency(sample time.Duration) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	p.processed++
+	p.window += sample
+	p.samples++
+}
+
+// updateLatencyLocked folds the current window into the moving average;
+// p.mu must be held
+func (p *Pool) updateLatencyLocked(depth int) {
+	switch {
+	case p.samples > 0:
+		// Exponentially weighted: each window counts for a third
+		avg := p.window / time.Duration(p.samples)
+		p.latency += (avg - p.latency) / 3
+	case depth == 0:
+		// Nothing finished and nothing waiting: the pool is idle, so
+		// let the old latency fade away
+		p.latency /= 2
+	}
+	p.window, p.samples = 0, 0
+}
+
+// Controller loop: checks the queue periodically and resizes the pool
+func (p *Pool) controller() {
+	defer p.controllerWg.Done()
+
+	ticker := time.NewTicker(p.config.CheckInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-p.done:
+			return
+		case now := <-ticker.C:
+			p.evaluate(now)
+		}
+	}
+}
+
+// evaluate makes at most one scaling decision
+func (p *Pool) evaluate(now time.Time) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	size := len(p.quits)
+	depth := len(p.jobs)
+	p.updateLatencyLocked(depth)
+	latency := p.latency
+
+	var reason string
+	to := size
+	switch {
+	case size == 0 && depth > 0:
+		// With no workers there is no latency to measure, so waiting
+		// jobs alone must start one, cooldown or not
+		reason = "jobs waiting with no workers"
+		to = 1
+		p.lastUp = time.Time{}
+	case size < p.config.MaxWorkers && depth > p.config.QueueHighWater:
+		reason = "queue above high water mark"
+		to = size + 1
+	case size < p.config.MaxWorkers && depth > 0 && latency > p.config.LatencyTarget:
+		reason = "latency above target"
+		to = size + 1
+	case size > p.config.MinWorkers && depth == 0 && latency < p.config.LatencyTarget/2:
+		reason = "queue empty and latency low"
+		to = size - 1
+	}
+
+	if to > size {
+		if now.Sub(p.lastUp) < p.config.ScaleUpCooldown {
+			return
+		}
+		p.startWorkerLocked()
+		p.lastUp = now
+	} else if to < size {
+		// Scaling down right after scaling up would just thrash
+		if now.Sub(p.lastDown) < p.config.ScaleDownCooldown || now.Sub(p.lastUp) < p.config.ScaleDownCooldown {
+			return
+		}
+		p.stopWorkerLocked()
+		p.lastDown = now
+	} else {
+		return
+	}
+
+	p.decisions = append(p.decisions, Decision{
+		Time:       now,
+		From:       size,
+		To:         to,
+		QueueDepth: depth,
+		Latency:    latency,
+		Reason:     reason,
+	})
+}
@@ -0,0 +1,142 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+)
+
+// Example 14: Tracing Goroutines
+// Demonstrates recording goroutine activity and exporting it in the Chrome
+// Trace Event format. Open the output in chrome://tracing or
+// https://ui.perfetto.dev to see the timeline.
+
+// Pipeline stage that sends numbers to a channel
+func generateNumbers(tracer *Tracer, count int, ch chan<- int) {
+	tracer.NameGoroutine("generator")
+	for i := 1; i <= count; i++ {
+		end := tracer.Span("generate", "pipeline", map[string]any{"value": i})
+		time.Sleep(time.Millisecond * 10)
+		end()
+		ch <- i
+	}
+	close(ch)
+}
+
+// Pipeline stage that squares numbers
+func squareNumbers(tracer *Tracer, in <-chan int, out chan<- int) {
+	tracer.NameGoroutine("squarer")
+	for num := range in {
+		end := tracer.Span("square", "pipeline", map[string]any{"value": num})
+		time.Sleep(time.Millisecond * 15) // Slower than the generator
+		end()
+		out <- num * num
+	}
+	close(out)
+}
+
+// Worker pool pattern with a span per job
+func worker(tracer *Tracer, id int, jobs <-chan int, results chan<- int, wg *sync.WaitGroup) {
+	defer wg.Done()
+	tracer.NameGoroutine(fmt.Sprintf("worker %d", id))
+
+	for job := range jobs {
+		end := tracer.Span(fmt.Sprintf("job %d", job), "worker", map[string]any{"job": job})
+		time.Sleep(time.Millisecond * time.Duration(20+job*5)) // Simulate work
+		end()
+		results <- job * 2
+	}
+}
+
+// Mutex example where every goroutine holds the lock for a while, so the
+// trace shows them queueing up behind each other
+func mutexExample(tracer *Tracer) int {
+	counter := 0
+	mutex := NewTracedMutex(tracer, "counter")
+	var wg sync.WaitGroup
+
+	for i := 0; i < 5; i++ {
+		wg.Add(1)
+		go func(id int) {
+			defer wg.Done()
+			tracer.NameGoroutine(fmt.Sprintf("incrementer %d", id))
+
+			for j := 0; j < 5; j++ {
+				mutex.Lock()
+				counter++
+				time.Sleep(time.Millisecond * 2) // Hold the lock to cause contention
+				mutex.Unlock()
+			}
+		}(i)
+	}
+
+	wg.Wait()
+	return counter
+}
+
+func main() {
+	out := flag.String("o", filepath.Join(os.TempDir(), "example14-trace.json"), "trace output file")
+	flag.Parse()
+
+	fmt.Println("Tracing Goroutines in Go:")
+	tracer := NewTracer()
+	tracer.NameGoroutine("main")
+
+	fmt.Println("\n=== Pipeline ===")
+	endPipeline := tracer.Span("pipeline", "main", nil)
+	numberChan := make(chan int)
+	squareChan := make(chan int)
+	go generateNumbers(tracer, 5, numberChan)
+	go squareNumbers(tracer, numberChan, squareChan)
+	for squared := range squareChan {
+		fmt.Println("Received squared result:", squared)
+	}
+	endPipeline()
+
+	fmt.Println("\n=== Worker Pool ===")
+	endPool := tracer.Span("worker pool", "main", nil)
+	numJobs := 6
+	jobs := make(chan int, numJobs)
+	results := make(chan int, numJobs)
+	var wg sync.WaitGroup
+	for w := 1; w <= 3; w++ {
+		wg.Add(1)
+		go worker(tracer, w, jobs, results, &wg)
+	}
+	for j := 1; j <= numJobs; j++ {
+		jobs <- j
+	}
+	close(jobs)
+	wg.Wait()
+	close(results)
+	for result := range results {
+		fmt.Println("Result:", result)
+	}
+	endPool()
+
+	fmt.Println("\n=== Mutex Contention ===")
+	endMutex := tracer.Span("mutex example", "main", nil)
+	fmt.Println("Final counter value:", mutexExample(tracer))
+	endMutex()
+
+	// Write the trace file
+	fmt.Println("\n=== Export ===")
+	file, err := os.Create(*out)
+	if err != nil {
+		fmt.Println("Error:", err)
+		return
+	}
+	defer file.Close()
+
+	if err := tracer.WriteJSON(file); err != nil {
+		fmt.Println("Error:", err)
+		return
+	}
+	fmt.Printf("Wrote %d events to %s\n", tracer.Len(), *out)
+	fmt.Println("Open it in chrome://tracing or https://ui.perfetto.dev")
+
+	fmt.Println("\nTracing examples completed")
+}
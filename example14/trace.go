@@ -0,0 +1,153 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"io"
+	"os"
+	"runtime"
+	"strconv"
+	"sync"
+	"time"
+)
+
+// Event is one entry in the Chrome Trace Event format. Times are in
+// microseconds since the tracer was created.
+type Event struct {
+	Name string         `json:"name"`
+	Cat  string         `json:"cat,omitempty"`
+	Ph   string         `json:"ph"` // "X" complete span, "M" metadata
+	TS   float64        `json:"ts"`
+	Dur  float64        `json:"dur,omitempty"`
+	PID  int            `json:"pid"`
+	TID  int            `json:"tid"`
+	Args map[string]any `json:"args,omitempty"`
+}
+
+// Tracer collects events from any number of goroutines
+type Tracer struct {
+	mu     sync.Mutex
+	start  time.Time
+	pid    int
+	events []Event
+}
+
+// Constructor for the tracer
+func NewTracer() *Tracer {
+	return &Tracer{start: time.Now(), pid: os.Getpid()}
+}
+
+// micros converts a point in time to trace microseconds
+func (t *Tracer) micros(at time.Time) float64 {
+	return float64(at.Sub(t.start).Nanoseconds()) / 1000
+}
+
+func (t *Tracer) add(e Event) {
+	t.mu.Lock()
+	t.events = append(t.events, e)
+	t.mu.Unlock()
+}
+
+// Span starts a span on the calling goroutine's track and returns the
+// function that ends it, so it can be used as: defer tracer.Span(...)()
+func (t *Tracer) Span(name, cat string, args map[string]any) func() {
+	tid := goroutineID()
+	begin := time.Now()
+	return func() {
+		t.add(Event{
+			Name: name,
+			Cat:  cat,
+			Ph:   "X",
+			TS:   t.micros(begin),
+			Dur:  float64(time.Since(begin).Nanoseconds()) / 1000,
+			PID:  t.pid,
+			TID:  tid,
+			Args: args,
+		})
+	}
+}
+
+// NameGoroutine labels the calling goroutine's track in the viewer
+func (t *Tracer) NameGoroutine(name string) {
+	t.add(Event{Name: "thread_name", Ph: "M", PID: t.pid, TID: goroutineID(), Args: map[string]any{"name": name}})
+}
+
+// WriteJSON writes the collected events as a trace file
+func (t *Tracer) WriteJSON(w io.Writer) error {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", " ")
+	return enc.Encode(struct {
+		TraceEvents     []Event `json:"traceEvents"`
+		DisplayTimeUnit string  `json:"displayTimeUnit"`
+	}{t.events, "ms"})
+}
+
+// Len returns the number of events recorded so far
+func (t *Tracer) Len() int {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	return len(t.events)
+}
+
+// goroutineID parses the current goroutine's ID out of its stack header,
+// which starts with "goroutine 18 [running]:". Go deliberately has no API
+// for this; it is only used here to give each goroutine its own track.
+func goroutineID() int {
+	var buf [64]byte
+	n := runtime.Stack(buf[:], false)
+	field := bytes.Fields(buf[:n])[1]
+	id, err := strconv.Atoi(string(field))
+	if err != nil {
+		return 0
+	}
+	return id
+}
+
+// TracedMutex is a sync.Mutex that records how long each goroutine waited
+// for the lock and how long it held it
+type TracedMutex struct {
+	mu       sync.Mutex
+	tracer   *Tracer
+	name     string
+	acquired time.Time // Only touched while the lock is held
+	tid      int
+}
+
+// Constructor for the traced mutex
+func NewTracedMutex(tracer *Tracer, name string) *TracedMutex {
+	return &TracedMutex{tracer: tracer, name: name}
+}
+
+func (m *TracedMutex) Lock() {
+	requested := time.Now()
+	m.mu.Lock()
+	m.acquired = time.Now()
+	m.tid = goroutineID()
+
+	m.tracer.add(Event{
+		Name: m.name + " wait",
+		Cat:  "mutex",
+		Ph:   "X",
+		TS:   m.tracer.micros(requested),
+		Dur:  float64(m.acquired.Sub(requested).Nanoseconds()) / 1000,
+		PID:  m.tracer.pid,
+		TID:  m.tid,
+	})
+}
+
+func (m *TracedMutex) Unlock() {
+	held := Event{
+		Name: m.name + " held",
+		Cat:  "mutex",
+		Ph:   "X",
+		TS:   m.tracer.micros(m.acquired),
+		Dur:  float64(time.Since(m.acquired).Nanoseconds()) / 1000,
+		PID:  m.tracer.pid,
+		TID:  m.tid,
+	}
+	m.mu.Unlock()
+	m.tracer.add(held)
+}
@@ -0,0 +1,176 @@
+package main
+
+import (
+	"runtime"
+	"sync"
+	"sync/atomic"
+)
+
+// Counter is the interface every implementation below satisfies
+type Counter interface {
+	Inc()
+	Value() int64
+}
+
+// MutexCounter guards the count with a sync.Mutex, like mutexExample in
+// example 9
+type MutexCounter struct {
+	mu sync.Mutex
+	n  int64
+}
+
+func (c *MutexCounter) Inc() {
+	c.mu.Lock()
+	c.n++
+	c.mu.Unlock()
+}
+
+func (c *MutexCounter) Value() int64 {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.n
+}
+
+// RWMutexCounter lets readers share the lock. Increments still take the
+// write lock, so it only helps when reads dominate.
+type RWMutexCounter struct {
+	mu sync.RWMutex
+	n  int64
+}
+
+func (c *RWMutexCounter) Inc() {
+	c.mu.Lock()
+	c.n++
+	c.mu.Unlock()
+}
+
+func (c *RWMutexCounter) Value() int64 {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	return c.n
+}
+
+// AtomicCounter uses a single atomic integer
+type AtomicCounter struct {
+	n atomic.Int64
+}
+
+func (c *AtomicCounter) Inc() {
+	c.n.Add(1)
+}
+
+func (c *AtomicCounter) Value() int64 {
+	return c.n.Load()
+}
+
+// Size of a CPU cache line on common hardware
+const cacheLineSize = 64
+
+// shard is padded to a full cache line so two shards never share one.
+// Without padding, CPUs updating neighbouring shards would still fight
+// over the same line ("false sharing").
+type shard struct {
+	n atomic.Int64
+	_ [cacheLineSize - 8]byte
+}
+
+// ShardedCounter spreads increments over one padded shard per P (the
+// scheduler's slot for running Go code, one per GOMAXPROCS) and adds them
+// up on read. Value is not a consistent snapshot while increments are in
+// progress, but it is exact once they have stopped.
+//
+// Go does not say which P a goroutine is running on, so shards are handed
+// out through a sync.Pool, whose per-P cache usually gives a goroutine the
+// shard last used on its P. That is only a tendency that keeps contention
+// low: the pool steals from other Ps when its own cache is empty, and a
+// garbage collection can drop shards so they are handed out again
+// round-robin. Two Ps can end up writing to the same shard, so correctness
+// rests entirely on every shard being updated atomically. The trade-offs:
+//   - Get and Put cost a few nanoseconds, so with one goroutine this is
+//     slower than AtomicCounter. It only wins when several CPUs increment
+//     at once and a single atomic would bounce between their caches.
+//   - Each counter costs a cache line per P, and Value reads all of them.
+type ShardedCounter struct {
+	shards []shard
+	next   atomic.Uint64 // Round-robin position for shards not in the pool
+	pool   sync.Pool
+}
+
+// Constructor for the sharded counter: one shard per P
+func NewShardedCounter() *ShardedCounter {
+	c := &ShardedCounter{shards: make([]shard, runtime.GOMAXPROCS(0))}
+	c.pool.New = func() any {
+		i := (c.next.Add(1) - 1) % uint64(len(c.shards))
+		return &c.shards[i]
+	}
+	return c
+}
+
+func (c *ShardedCounter) Inc() {
+	s := c.pool.Get().(*shard)
+	s.n.Add(1)
+	c.pool.Put(s)
+}
+
+func (c *ShardedCounter) Value() int64 {
+	var total int64
+	for i := range c.shards {
+		total += c.shards[i].n.Load()
+	}
+	return total
+}
+
+// ChannelCounter keeps the count inside one goroutine that owns it. Other
+// goroutines never touch the count, they send requests instead.
+type ChannelCounter struct {
+	inc   chan struct{}
+	value chan chan int64
+	done  chan struct{}
+}
+
+// Constructor for the channel counter; starts the owning goroutine
+func NewChannelCounter() *ChannelCounter {
+	c := &ChannelCounter{
+		inc:   make(chan struct{}, 128),
+		value: make(chan chan int64),
+		done:  make(chan struct{}),
+	}
+	go c.run()
+	return c
+}
+
+// Owner loop: the only code that reads or writes n
+func (c *ChannelCounter) run() {
+	var n int64
+	for {
+		select {
+		case <-c.inc:
+			n++
+		case reply := <-c.value:
+			// Apply increments already sent before answering, so a
+			// caller always sees its own increments
+			for len(c.inc) > 0 {
+				<-c.inc
+				n++
+			}
+			reply <- n
+		case <-c.done:
+			return
+		}
+	}
+}
+
+func (c *ChannelCounter) Inc() {
+	c.inc <- struct{}{}
+}
+
+func (c *ChannelCounter) Value() int64 {
+	reply := make(chan int64)
+	c.value <- reply
+	return <-reply
+}
+
+// Close stops the owning goroutine
+func (c *ChannelCounter) Close() {
+	close(c.done)
+}
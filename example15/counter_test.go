@@ -0,0 +1,65 @@
+package main
+
+import (
+	"fmt"
+	"testing"
+)
+
+// Function that closes counters owning a goroutine
+func closeCounter(counter Counter) {
+	if closer, ok := counter.(interface{ Close() }); ok {
+		closer.Close()
+	}
+}
+
+func TestCounters(t *testing.T) {
+	for _, impl := range implementations {
+		for _, goroutines := range []int{1, 3, 8} {
+			// Fewer increments than goroutines must not lose any either
+			for _, ops := range []int{1, 7, 10000} {
+				counter := impl.make()
+				incrementConcurrently(counter, goroutines, ops)
+				if got := counter.Value(); got != int64(ops) {
+					t.Errorf("%s, %d goroutines: counted %d, want %d", impl.name, goroutines, got, ops)
+				}
+				closeCounter(counter)
+			}
+		}
+	}
+}
+
+func TestMeasureRejectsNoWork(t *testing.T) {
+	counter := &AtomicCounter{}
+	if _, err := measure(counter, 4, 0); err == nil {
+		t.Error("expected an error for zero increments")
+	}
+	if _, err := measure(counter, 0, 10); err == nil {
+		t.Error("expected an error for zero goroutines")
+	}
+	if _, err := measure(counter, 4, 3); err != nil {
+		t.Errorf("fewer increments than goroutines: %v", err)
+	}
+}
+
+// BenchmarkCounters compares the implementations across goroutine counts.
+// Run it with -cpu to vary GOMAXPROCS as well, for example:
+//
+//	go test -bench Counters -cpu 1,4,8 ./example15
+func BenchmarkCounters(b *testing.B) {
+	for _, impl := range implementations {
+		for _, goroutines := range []int{1, 2, 4, 8, 16} {
+			b.Run(fmt.Sprintf("%s/goroutines=%d", impl.name, goroutines), func(b *testing.B) {
+				counter := impl.make()
+				defer closeCounter(counter)
+
+				b.ResetTimer()
+				incrementConcurrently(counter, goroutines, b.N)
+				b.StopTimer()
+
+				if got := counter.Value(); got != int64(b.N) {
+					b.Fatalf("counted %d, want %d", got, b.N)
+				}
+			})
+		}
+	}
+}
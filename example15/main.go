@@ -0,0 +1,175 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"runtime"
+	"strconv"
+	"strings"
+	"sync"
+	"text/tabwriter"
+	"time"
+)
+
+// Example 15: Counter Benchmarks
+// Demonstrates several thread-safe counters behind one interface and a
+// small benchmark that compares them across goroutine counts
+
+// implementation names a counter constructor for the report
+type implementation struct {
+	name string
+	make func() Counter
+}
+
+var implementations = []implementation{
+	{"mutex", func() Counter { return &MutexCounter{} }},
+	{"rwmutex", func() Counter { return &RWMutexCounter{} }},
+	{"atomic", func() Counter { return &AtomicCounter{} }},
+	{"sharded", func() Counter { return NewShardedCounter() }},
+	{"channel", func() Counter { return NewChannelCounter() }},
+}
+
+// Function that spreads ops increments as evenly as possible over the
+// given number of goroutines and waits for them to finish. Each goroutine
+// gets ops/goroutines, and the first ops%goroutines get one more, so no
+// increments are lost when ops does not divide evenly.
+func incrementConcurrently(counter Counter, goroutines, ops int) {
+	var wg sync.WaitGroup
+	for g := 0; g < goroutines; g++ {
+		n := ops / goroutines
+		if g < ops%goroutines {
+			n++
+		}
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := 0; i < n; i++ {
+				counter.Inc()
+			}
+		}()
+	}
+	wg.Wait()
+}
+
+// Function that times ops increments over the given number of goroutines
+// and returns the nanoseconds per increment
+func measure(counter Counter, goroutines, ops int) (float64, error) {
+	if goroutines < 1 || ops < 1 {
+		return 0, fmt.Errorf("need at least one goroutine and one increment, got %d and %d", goroutines, ops)
+	}
+
+	start := time.Now()
+	incrementConcurrently(counter, goroutines, ops)
+	elapsed := time.Since(start)
+
+	// A fast counter is useless if it loses increments
+	if got := counter.Value(); got != int64(ops) {
+		return 0, fmt.Errorf("counted %d, want %d", got, ops)
+	}
+	return float64(elapsed.Nanoseconds()) / float64(ops), nil
+}
+
+// Function that runs every implementation at every goroutine count and
+// keeps the best of several runs
+func benchmark(goroutineCounts []int, ops, runs int) ([][]float64, error) {
+	table := make([][]float64, len(implementations))
+	for i, impl := range implementations {
+		table[i] = make([]float64, len(goroutineCounts))
+		for j, goroutines := range goroutineCounts {
+			best := 0.0
+			for r := 0; r < runs; r++ {
+				counter := impl.make()
+				perOp, err := measure(counter, goroutines, ops)
+				if closer, ok := counter.(interface{ Close() }); ok {
+					closer.Close()
+				}
+				if err != nil {
+					return nil, fmt.Errorf("%s with %d goroutines: %w", impl.name, goroutines, err)
+				}
+				if best == 0 || perOp < best {
+					best = perOp
+				}
+			}
+			table[i][j] = best
+		}
+	}
+	return table, nil
+}
+
+// Function that prints the results as a table, marking the fastest
+// implementation in each column with a star
+func printReport(w io.Writer, goroutineCounts []int, table [][]float64) {
+	tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', tabwriter.AlignRight)
+	fmt.Fprint(tw, "ns/op\t")
+	for _, g := range goroutineCounts {
+		fmt.Fprintf(tw, "%d goroutines\t", g)
+	}
+	fmt.Fprintln(tw)
+
+	for i, impl := range implementations {
+		fmt.Fprintf(tw, "%s\t", impl.name)
+		for j := range goroutineCounts {
+			mark := ""
+			if isFastest(table, i, j) {
+				mark = " *"
+			}
+			fmt.Fprintf(tw, "%.1f%s\t", table[i][j], mark)
+		}
+		fmt.Fprintln(tw)
+	}
+	tw.Flush()
+}
+
+// Function that reports whether row i has the lowest value in column j
+func isFastest(table [][]float64, i, j int) bool {
+	for k := range table {
+		if table[k][j] < table[i][j] {
+			return false
+		}
+	}
+	return true
+}
+
+// Function that parses a comma-separated list of positive integers
+func parseCounts(s string) ([]int, error) {
+	var counts []int
+	for _, field := range strings.Split(s, ",") {
+		n, err := strconv.Atoi(strings.TrimSpace(field))
+		if err != nil || n <= 0 {
+			return nil, fmt.Errorf("invalid goroutine count %q", field)
+		}
+		counts = append(counts, n)
+	}
+	return counts, nil
+}
+
+func main() {
+	goroutines := flag.String("goroutines", "1,2,4,8,16", "comma-separated goroutine counts")
+	ops := flag.Int("ops", 400000, "increments per measurement")
+	runs := flag.Int("runs", 3, "runs per measurement; the fastest is reported")
+	flag.Parse()
+
+	counts, err := parseCounts(*goroutines)
+	if err != nil {
+		fmt.Println("Error:", err)
+		os.Exit(2)
+	}
+	if *ops < 1 || *runs < 1 {
+		fmt.Println("Error: -ops and -runs must be at least 1")
+		os.Exit(2)
+	}
+
+	fmt.Println("Counter Benchmarks in Go:")
+	fmt.Printf("GOMAXPROCS=%d, %d increments per run, best of %d runs\n\n", runtime.GOMAXPROCS(0), *ops, *runs)
+
+	table, err := benchmark(counts, *ops, *runs)
+	if err != nil {
+		fmt.Println("Error:", err)
+		os.Exit(1)
+	}
+	printReport(os.Stdout, counts, table)
+
+	fmt.Println("\nCounter benchmark completed")
+}
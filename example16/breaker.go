@@ -0,0 +1,229 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+)
+
+// State of a circuit breaker
+type State int
+
+const (
+	StateClosed   State = iota // Calls go through; failures are counted
+	StateOpen                  // Calls fail fast until the reset timeout
+	StateHalfOpen              // A few trial calls decide whether to close
+)
+
+func (s State) String() string {
+	switch s {
+	case StateClosed:
+		return "closed"
+	case StateOpen:
+		return "open"
+	case StateHalfOpen:
+		return "half-open"
+	}
+	return fmt.Sprintf("State(%d)", int(s))
+}
+
+// ErrCircuitOpen is returned instead of calling a dependency that is failing
+var ErrCircuitOpen = errors.New("circuit breaker is open")
+
+// BreakerConfig sets when the breaker trips and recovers
+type BreakerConfig struct {
+	// Consecutive failures that open the breaker
+	FailureThreshold int
+	// How long the breaker stays open before allowing trial calls
+	ResetTimeout time.Duration
+	// Successful trial calls needed to close the breaker again; defaults to 1
+	HalfOpenSuccesses int
+	// Called on every state change, if set. It runs after the breaker's
+	// lock is released, so it may call the breaker.
+	OnStateChange func(from, to State)
+}
+
+// transition is a state change waiting to be reported to OnStateChange
+type transition struct {
+	from, to State
+}
+
+// CircuitBreaker stops calls to a dependency after repeated failures so the
+// callers fail fast instead of piling up behind it
+type CircuitBreaker struct {
+	config BreakerConfig
+	now    func() time.Time
+
+	mu        sync.Mutex
+	state     State
+	failures  int // Consecutive failures while closed
+	successes int // Successful trial calls while half-open
+	trials    int // Trial calls in progress while half-open
+	openedAt  time.Time
+	// Incremented on every state change, so results of calls allowed in
+	// an earlier state can be told apart
+	generation uint64
+	pending    []transition // Not yet passed to OnStateChange
+}
+
+// Constructor for the circuit breaker
+func NewCircuitBreaker(config BreakerConfig) *CircuitBreaker {
+	if config.HalfOpenSuccesses <= 0 {
+		config.HalfOpenSuccesses = 1
+	}
+	return &CircuitBreaker{config: config, now: time.Now}
+}
+
+// unlock releases b.mu, then reports any state changes made while it was
+// held. Every method that can change state unlocks through here.
+func (b *CircuitBreaker) unlock() {
+	pending := b.pending
+	b.pending = nil
+	b.mu.Unlock()
+
+	if b.config.OnStateChange != nil {
+		for _, t := range pending {
+			b.config.OnStateChange(t.from, t.to)
+		}
+	}
+}
+
+// State returns the current state, moving from open to half-open if the
+// reset timeout has passed
+func (b *CircuitBreaker) State() State {
+	b.mu.Lock()
+	defer b.unlock()
+	b.checkResetLocked()
+	return b.state
+}
+
+// Allow reports whether a call may go ahead. Every allowed call must be
+// followed by exactly one Record, passing back the generation returned.
+func (b *CircuitBreaker) Allow() (uint64, error) {
+	b.mu.Lock()
+	defer b.unlock()
+
+	b.checkResetLocked()
+	switch b.state {
+	case StateOpen:
+		return 0, ErrCircuitOpen
+	case StateHalfOpen:
+		// Only let through as many trial calls as are needed to close
+		if b.trials >= b.config.HalfOpenSuccesses-b.successes {
+			return 0, ErrCircuitOpen
+		}
+		b.trials++
+	}
+	return b.generation, nil
+}
+
+// Record reports the outcome of a call allowed by Allow. Results from a
+// generation before the latest state change are ignored: a slow call let
+// through while closed must not count as a trial once half-open.
+func (b *CircuitBreaker) Record(generation uint64, err error) {
+	b.mu.Lock()
+	defer b.unlock()
+
+	if generation != b.generation {
+		return
+	}
+	switch b.state {
+	case StateClosed:
+		if err == nil {
+			b.failures = 0
+			return
+		}
+		b.failures++
+		if b.failures >= b.config.FailureThreshold {
+			b.setStateLocked(StateOpen)
+		}
+	case StateHalfOpen:
+		b.trials--
+		if err != nil {
+			// The dependency is still failing: back off again
+			b.setStateLocked(StateOpen)
+			return
+		}
+		b.successes++
+		if b.successes >= b.config.HalfOpenSuccesses {
+			b.setStateLocked(StateClosed)
+		}
+	}
+}
+
+// checkResetLocked moves an open breaker to half-open once the reset
+// timeout has passed; b.mu must be held
+func (b *CircuitBreaker) checkResetLocked() {
+	if b.state == StateOpen && b.now().Sub(b.openedAt) >= b.config.ResetTimeout {
+		b.setStateLocked(StateHalfOpen)
+	}
+}
+
+// setStateLocked changes state and resets the counters; b.mu must be held
+func (b *CircuitBreaker) setStateLocked(to State) {
+	from := b.state
+	b.state = to
+	b.failures, b.successes, b.trials = 0, 0, 0
+	b.generation++
+	if to == StateOpen {
+		b.openedAt = b.now()
+	}
+	b.pending = append(b.pending, transition{from, to})
+}
+
+// WithTimeout runs fn with a deadline. If the deadline passes first it
+// returns straight away with the context's error. fn gets a context that is
+// cancelled at the deadline and should give up when it sees that; the
+// result channel is buffered so fn can still finish without leaking.
+func WithTimeout[T any](ctx context.Context, timeout time.Duration, fn func(context.Context) (T, error)) (T, error) {
+	ctx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+
+	type result struct {
+		value T
+		err   error
+	}
+	done := make(chan result, 1)
+	go func() {
+		value, err := fn(ctx)
+		done <- result{value, err}
+	}()
+
+	select {
+	case r := <-done:
+		return r.value, r.err
+	case <-ctx.Done():
+		var zero T
+		return zero, ctx.Err()
+	}
+}
+
+// Guard wraps fn so calls go through the breaker. A panic in fn is
+// recorded as a failure before it carries on up the stack, so a half-open
+// breaker is never left waiting on a trial call that will not report back.
+func Guard[T any](b *CircuitBreaker, fn func(context.Context) (T, error)) func(context.Context) (T, error) {
+	return func(ctx context.Context) (value T, err error) {
+		generation, err := b.Allow()
+		if err != nil {
+			return value, err
+		}
+		defer func() {
+			if r := recover(); r != nil {
+				b.Record(generation, fmt.Errorf("panic: %v", r))
+				panic(r)
+			}
+			b.Record(generation, err)
+		}()
+		return fn(ctx)
+	}
+}
+
+// Protect combines both wrappers: each call gets its own timeout, and
+// timeouts count as failures for the breaker
+func Protect[T any](b *CircuitBreaker, timeout time.Duration, fn func(context.Context) (T, error)) func(context.Context) (T, error) {
+	return Guard(b, func(ctx context.Context) (T, error) {
+		return WithTimeout(ctx, timeout, fn)
+	})
+}
@@ -0,0 +1,115 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// Example 16: Circuit Breakers and Timeouts
+// Demonstrates keeping a worker pool responsive when a dependency hangs,
+// using per-call timeouts and a circuit breaker
+
+// flakyService simulates a local service that hangs for a while
+type flakyService struct {
+	calls    atomic.Int64
+	downFrom time.Time
+	downTo   time.Time
+}
+
+// Lookup doubles n, or hangs until the context is done while the service
+// is down
+func (s *flakyService) Lookup(ctx context.Context, n int) (int, error) {
+	s.calls.Add(1)
+	if now := time.Now(); now.After(s.downFrom) && now.Before(s.downTo) {
+		<-ctx.Done() // Hang like a stuck connection
+		return 0, ctx.Err()
+	}
+	select {
+	case <-time.After(time.Millisecond * 10):
+		return n * 2, nil
+	case <-ctx.Done():
+		return 0, ctx.Err()
+	}
+}
+
+// Worker pool pattern where each job calls the protected service
+func worker(id int, jobs <-chan int, call func(context.Context, int) (int, error), wg *sync.WaitGroup) {
+	defer wg.Done()
+
+	for job := range jobs {
+		start := time.Now()
+		result, err := call(context.Background(), job)
+		elapsed := time.Since(start).Round(time.Millisecond)
+		switch {
+		case errors.Is(err, ErrCircuitOpen):
+			fmt.Printf("Worker %d job %d: failed fast (%v)\n", id, job, elapsed)
+		case errors.Is(err, context.DeadlineExceeded):
+			fmt.Printf("Worker %d job %d: timed out (%v)\n", id, job, elapsed)
+		case err != nil:
+			fmt.Printf("Worker %d job %d: error %v\n", id, job, err)
+		default:
+			fmt.Printf("Worker %d job %d: result %d (%v)\n", id, job, result, elapsed)
+		}
+		time.Sleep(time.Millisecond * 20) // Jobs arrive over time
+	}
+}
+
+func main() {
+	fmt.Println("Circuit Breakers and Timeouts in Go:")
+
+	// Timeout on its own
+	fmt.Println("\n=== Timeout ===")
+	_, err := WithTimeout(context.Background(), time.Millisecond*50, func(ctx context.Context) (string, error) {
+		select {
+		case <-time.After(time.Second):
+			return "too late", nil
+		case <-ctx.Done():
+			return "", ctx.Err()
+		}
+	})
+	fmt.Println("Slow call:", err)
+
+	// The service hangs for a while; without protection every call in
+	// that window would block a worker
+	fmt.Println("\n=== Worker Pool with Circuit Breaker ===")
+	now := time.Now()
+	service := &flakyService{
+		downFrom: now.Add(time.Millisecond * 50),
+		downTo:   now.Add(time.Millisecond * 200),
+	}
+	breaker := NewCircuitBreaker(BreakerConfig{
+		FailureThreshold:  3,
+		ResetTimeout:      time.Millisecond * 100,
+		HalfOpenSuccesses: 2,
+		OnStateChange: func(from, to State) {
+			fmt.Printf("Circuit breaker: %s -> %s\n", from, to)
+		},
+	})
+
+	call := func(ctx context.Context, n int) (int, error) {
+		return Protect(breaker, time.Millisecond*40, func(ctx context.Context) (int, error) {
+			return service.Lookup(ctx, n)
+		})(ctx)
+	}
+
+	jobs := make(chan int)
+	var wg sync.WaitGroup
+	for w := 1; w <= 3; w++ {
+		wg.Add(1)
+		go worker(w, jobs, call, &wg)
+	}
+	for j := 1; j <= 60; j++ {
+		jobs <- j
+	}
+	close(jobs)
+	wg.Wait()
+
+	fmt.Println("Final state:", breaker.State())
+	fmt.Println("Calls that reached the service:", service.calls.Load())
+
+	fmt.Println("\nCircuit breaker examples completed")
+}
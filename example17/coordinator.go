@@ -0,0 +1,256 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"net"
+	"net/rpc"
+	"sort"
+	"sync"
+	"time"
+)
+
+// Job is a CPU-heavy unit of work: count the primes up to N
+type Job struct {
+	ID int
+	N  int
+}
+
+// RPC argument and reply types. net/rpc needs them exported.
+type RegisterArgs struct {
+	Name string
+}
+
+type RegisterReply struct {
+	WorkerID int
+}
+
+type WorkerArgs struct {
+	WorkerID int
+}
+
+type JobReply struct {
+	Job    Job
+	HasJob bool // False when every job is assigned but not all are done
+	Done   bool // True when every job is finished and the worker can exit
+}
+
+type ResultArgs struct {
+	WorkerID int
+	JobID    int
+	Result   int
+}
+
+// ErrUnknownWorker is returned to workers the coordinator has given up on
+var ErrUnknownWorker = errors.New("unknown worker")
+
+// ErrNotAssigned is returned for a result for a job that does not exist
+// or was never handed to the worker submitting it
+var ErrNotAssigned = errors.New("job not assigned to this worker")
+
+// assignment records which worker is running a job
+type assignment struct {
+	job      Job
+	workerID int
+}
+
+// Coordinator hands out jobs over RPC and collects the results. Jobs held
+// by a worker that stops heartbeating are put back in the queue.
+type Coordinator struct {
+	heartbeatTimeout time.Duration
+
+	mu        sync.Mutex
+	pending   []Job
+	assigned  map[int]assignment   // By job ID
+	given     map[int]map[int]bool // Every worker each job was handed to
+	workers   map[int]time.Time    // Last heartbeat by worker ID
+	names     map[int]string
+	results   map[int]int // By job ID
+	total     int
+	nextID    int
+	allDone   chan struct{}
+	closeOnce sync.Once
+}
+
+// DefaultHeartbeatTimeout is used when NewCoordinator is given a timeout
+// that is not positive
+const DefaultHeartbeatTimeout = time.Second
+
+// Constructor for the coordinator
+func NewCoordinator(jobs []Job, heartbeatTimeout time.Duration) *Coordinator {
+	if heartbeatTimeout <= 0 {
+		heartbeatTimeout = DefaultHeartbeatTimeout
+	}
+	c := &Coordinator{
+		heartbeatTimeout: heartbeatTimeout,
+		pending:          append([]Job(nil), jobs...),
+		assigned:         make(map[int]assignment),
+		given:            make(map[int]map[int]bool),
+		workers:          make(map[int]time.Time),
+		names:            make(map[int]string),
+		results:          make(map[int]int),
+		total:            len(jobs),
+		nextID:           1,
+		allDone:          make(chan struct{}),
+	}
+	// With no jobs there is no result to wait for
+	if c.total == 0 {
+		c.closeOnce.Do(func() { close(c.allDone) })
+	}
+	return c
+}
+
+// Register gives a new worker process its ID
+func (c *Coordinator) Register(args RegisterArgs, reply *RegisterReply) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	reply.WorkerID = c.nextID
+	c.nextID++
+	c.workers[reply.WorkerID] = time.Now()
+	c.names[reply.WorkerID] = args.Name
+	fmt.Printf("Coordinator: worker %d (%s) registered\n", reply.WorkerID, args.Name)
+	return nil
+}
+
+// Heartbeat tells the coordinator a worker is still alive
+func (c *Coordinator) Heartbeat(args WorkerArgs, reply *struct{}) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if _, ok := c.workers[args.WorkerID]; !ok {
+		return ErrUnknownWorker
+	}
+	c.workers[args.WorkerID] = time.Now()
+	return nil
+}
+
+// RequestJob hands the next pending job to a worker
+func (c *Coordinator) RequestJob(args WorkerArgs, reply *JobReply) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if _, ok := c.workers[args.WorkerID]; !ok {
+		return ErrUnknownWorker
+	}
+	if len(c.results) == c.total {
+		reply.Done = true
+		return nil
+	}
+	if len(c.pending) == 0 {
+		return nil // Everything is assigned; ask again later
+	}
+
+	job := c.pending[0]
+	c.pending = c.pending[1:]
+	c.assigned[job.ID] = assignment{job: job, workerID: args.WorkerID}
+	if c.given[job.ID] == nil {
+		c.given[job.ID] = make(map[int]bool)
+	}
+	c.given[job.ID][args.WorkerID] = true
+	reply.Job = job
+	reply.HasJob = true
+	return nil
+}
+
+// SubmitResult records a finished job. A worker may only submit jobs it
+// was handed, but a late result from a worker that was reaped still
+// counts: the work is done, so the job is not run again.
+func (c *Coordinator) SubmitResult(args ResultArgs, reply *struct{}) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if !c.given[args.JobID][args.WorkerID] {
+		return fmt.Errorf("job %d from worker %d: %w", args.JobID, args.WorkerID, ErrNotAssigned)
+	}
+	// A reassigned job may be finished twice; the first result wins
+	if _, done := c.results[args.JobID]; done {
+		return nil
+	}
+	c.results[args.JobID] = args.Result
+	delete(c.assigned, args.JobID)
+
+	// Drop the copy requeued when the worker was reaped, if it has not
+	// been handed out again
+	for i, job := range c.pending {
+		if job.ID == args.JobID {
+			c.pending = append(c.pending[:i], c.pending[i+1:]...)
+			break
+		}
+	}
+
+	if len(c.results) == c.total {
+		c.closeOnce.Do(func() { close(c.allDone) })
+	}
+	return nil
+}
+
+// reapLocked forgets workers that missed their heartbeats and requeues their
+// jobs; c.mu must be held
+func (c *Coordinator) reapLocked(now time.Time) {
+	for id, last := range c.workers {
+		if now.Sub(last) < c.heartbeatTimeout {
+			continue
+		}
+		fmt.Printf("Coordinator: worker %d (%s) missed its heartbeats\n", id, c.names[id])
+		delete(c.workers, id)
+
+		for jobID, a := range c.assigned {
+			if a.workerID == id {
+				fmt.Printf("Coordinator: reassigning job %d\n", jobID)
+				delete(c.assigned, jobID)
+				c.pending = append(c.pending, a.job)
+			}
+		}
+	}
+}
+
+// Serve accepts connections on l until every job is done, checking for dead
+// workers in the background. It returns the results ordered by job ID.
+func (c *Coordinator) Serve(l net.Listener) ([][2]int, error) {
+	server := rpc.NewServer()
+	if err := server.Register(c); err != nil {
+		l.Close()
+		return nil, fmt.Errorf("failed to register coordinator: %w", err)
+	}
+	go func() {
+		for {
+			conn, err := l.Accept()
+			if err != nil {
+				return // Listener closed
+			}
+			go server.ServeConn(conn)
+		}
+	}()
+
+	ticker := time.NewTicker(c.heartbeatTimeout / 2)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case now := <-ticker.C:
+			c.mu.Lock()
+			c.reapLocked(now)
+			c.mu.Unlock()
+		case <-c.allDone:
+			// Give idle workers a moment to ask again and hear that
+			// they are done before the listener goes away
+			time.Sleep(c.heartbeatTimeout)
+			l.Close()
+			return c.sortedResults(), nil
+		}
+	}
+}
+
+// sortedResults returns (job ID, result) pairs in job order
+func (c *Coordinator) sortedResults() [][2]int {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	results := make([][2]int, 0, len(c.results))
+	for id, result := range c.results {
+		results = append(results, [2]int{id, result})
+	}
+	sort.Slice(results, func(i, j int) bool { return results[i][0] < results[j][0] })
+	return results
+}
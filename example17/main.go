@@ -0,0 +1,150 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"net"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strconv"
+	"sync"
+	"time"
+)
+
+// Example 17: Multi-Process Worker Pool
+// Demonstrates the worker pool pattern across processes: a coordinator
+// hands out jobs over net/rpc, and separate worker processes connect,
+// heartbeat and return results. Jobs from dead workers are reassigned.
+//
+// Run without flags for a demo that starts the coordinator and three worker
+// processes. Or run the parts yourself:
+//
+//	go run ./example17 -mode coordinator -addr 127.0.0.1:7070
+//	go run ./example17 -mode worker -addr 127.0.0.1:7070
+
+const heartbeatInterval = time.Millisecond * 100
+
+// Function that builds the job list
+func makeJobs(count int) []Job {
+	jobs := make([]Job, count)
+	for i := range jobs {
+		jobs[i] = Job{ID: i + 1, N: 200000 + i*50000}
+	}
+	return jobs
+}
+
+// Function that runs the coordinator and prints the results
+func runCoordinator(l net.Listener, numJobs int) error {
+	coordinator := NewCoordinator(makeJobs(numJobs), heartbeatInterval*3)
+	fmt.Printf("Coordinator listening on %s %s\n", l.Addr().Network(), l.Addr())
+
+	results, err := coordinator.Serve(l)
+	if err != nil {
+		return err
+	}
+	for _, r := range results {
+		fmt.Printf("Result: job %d found %d primes\n", r[0], r[1])
+	}
+	return nil
+}
+
+// Function that starts worker processes by re-running this program
+func startWorkers(network, addr string, count, crashAfter int) ([]*exec.Cmd, error) {
+	exe, err := os.Executable()
+	if err != nil {
+		return nil, fmt.Errorf("failed to find executable: %w", err)
+	}
+
+	var cmds []*exec.Cmd
+	for w := 1; w <= count; w++ {
+		args := []string{"-mode", "worker", "-network", network, "-addr", addr, "-name", fmt.Sprintf("worker-%d", w)}
+		if w == 1 && crashAfter > 0 {
+			args = append(args, "-crash-after", strconv.Itoa(crashAfter))
+		}
+		cmd := exec.Command(exe, args...)
+		cmd.Stdout = os.Stdout
+		cmd.Stderr = os.Stderr
+		if err := cmd.Start(); err != nil {
+			return cmds, fmt.Errorf("failed to start worker: %w", err)
+		}
+		cmds = append(cmds, cmd)
+	}
+	return cmds, nil
+}
+
+// Function that runs the whole demo in one command
+func runDemo(network string, numWorkers, numJobs int) error {
+	addr := "127.0.0.1:0"
+	if network == "unix" {
+		dir, err := os.MkdirTemp("", "example17")
+		if err != nil {
+			return err
+		}
+		defer os.RemoveAll(dir)
+		addr = filepath.Join(dir, "coordinator.sock")
+	}
+
+	l, err := net.Listen(network, addr)
+	if err != nil {
+		return fmt.Errorf("failed to listen: %w", err)
+	}
+
+	// The first worker crashes after one job so its second is reassigned
+	cmds, err := startWorkers(network, l.Addr().String(), numWorkers, 1)
+	if err != nil {
+		l.Close()
+		return err
+	}
+
+	coordErr := runCoordinator(l, numJobs)
+
+	var wg sync.WaitGroup
+	for _, cmd := range cmds {
+		wg.Add(1)
+		go func(cmd *exec.Cmd) {
+			defer wg.Done()
+			if err := cmd.Wait(); err != nil {
+				fmt.Printf("Worker process %d exited: %v\n", cmd.Process.Pid, err)
+			}
+		}(cmd)
+	}
+	wg.Wait()
+	return coordErr
+}
+
+func main() {
+	mode := flag.String("mode", "demo", "demo, coordinator or worker")
+	network := flag.String("network", "tcp", "tcp or unix")
+	addr := flag.String("addr", "", "address to listen on or connect to")
+	name := flag.String("name", "worker", "worker name for log output")
+	numWorkers := flag.Int("workers", 3, "worker processes to start in demo mode")
+	numJobs := flag.Int("jobs", 8, "number of jobs")
+	crashAfter := flag.Int("crash-after", 0, "worker exits during the job after this many (0 = never)")
+	flag.Parse()
+
+	var err error
+	switch *mode {
+	case "demo":
+		fmt.Println("Multi-Process Worker Pool in Go:")
+		err = runDemo(*network, *numWorkers, *numJobs)
+		if err == nil {
+			fmt.Println("\nMulti-process worker pool example completed")
+		}
+	case "coordinator":
+		var l net.Listener
+		l, err = net.Listen(*network, *addr)
+		if err == nil {
+			err = runCoordinator(l, *numJobs)
+		}
+	case "worker":
+		err = runWorker(*network, *addr, *name, heartbeatInterval, *crashAfter)
+	default:
+		err = fmt.Errorf("unknown mode %q", *mode)
+	}
+
+	if err != nil {
+		fmt.Println("Error:", err)
+		os.Exit(1)
+	}
+}
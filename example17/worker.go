@@ -0,0 +1,89 @@
+package main
+
+import (
+	"fmt"
+	"net/rpc"
+	"os"
+	"time"
+)
+
+// countPrimes is the CPU-heavy work: a deliberately naive prime count
+func countPrimes(n int) int {
+	count := 0
+	for i := 2; i <= n; i++ {
+		prime := true
+		for d := 2; d*d <= i; d++ {
+			if i%d == 0 {
+				prime = false
+				break
+			}
+		}
+		if prime {
+			count++
+		}
+	}
+	return count
+}
+
+// runWorker connects to the coordinator and processes jobs until told to
+// stop. If crashAfter is positive the process exits in the middle of job
+// number crashAfter+1, to show jobs being reassigned.
+func runWorker(network, addr, name string, heartbeat time.Duration, crashAfter int) error {
+	client, err := rpc.Dial(network, addr)
+	if err != nil {
+		return fmt.Errorf("failed to connect to coordinator: %w", err)
+	}
+	defer client.Close()
+
+	var reg RegisterReply
+	if err := client.Call("Coordinator.Register", RegisterArgs{Name: name}, &reg); err != nil {
+		return fmt.Errorf("failed to register: %w", err)
+	}
+	args := WorkerArgs{WorkerID: reg.WorkerID}
+
+	// Heartbeat in the background while jobs run
+	stop := make(chan struct{})
+	defer close(stop)
+	go func() {
+		ticker := time.NewTicker(heartbeat)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-stop:
+				return
+			case <-ticker.C:
+				client.Call("Coordinator.Heartbeat", args, &struct{}{})
+			}
+		}
+	}()
+
+	completed := 0
+	for {
+		var reply JobReply
+		if err := client.Call("Coordinator.RequestJob", args, &reply); err != nil {
+			return fmt.Errorf("failed to request job: %w", err)
+		}
+		if reply.Done {
+			fmt.Printf("%s: no more jobs, %d completed\n", name, completed)
+			return nil
+		}
+		if !reply.HasJob {
+			time.Sleep(heartbeat)
+			continue
+		}
+
+		job := reply.Job
+		fmt.Printf("%s: processing job %d (primes up to %d)\n", name, job.ID, job.N)
+		if crashAfter > 0 && completed == crashAfter {
+			fmt.Printf("%s: crashing during job %d\n", name, job.ID)
+			os.Exit(1)
+		}
+
+		result := countPrimes(job.N)
+		err := client.Call("Coordinator.SubmitResult", ResultArgs{WorkerID: reg.WorkerID, JobID: job.ID, Result: result}, &struct{}{})
+		if err != nil {
+			return fmt.Errorf("failed to submit result: %w", err)
+		}
+		completed++
+	}
+}
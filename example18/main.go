@@ -0,0 +1,157 @@
+package main
+
+import (
+	"fmt"
+	"math/rand"
+	"sync"
+	"time"
+)
+
+// Example 18: Cron Scheduler
+// Demonstrates a scheduler that parses cron expressions and submits
+// recurring jobs into a worker pool, instead of loops with time.Sleep
+
+// Worker pool pattern: workers execute whatever the scheduler submits
+func worker(id int, runs <-chan Run, wg *sync.WaitGroup) {
+	defer wg.Done()
+
+	for run := range runs {
+		fmt.Printf("Worker %d running %s\n", id, run.Name)
+		run.Execute()
+	}
+}
+
+// Function that executes runs until none arrive for a moment, standing in
+// for the worker pool when the clock is fake
+func drain(runs <-chan Run) {
+	for {
+		select {
+		case run := <-runs:
+			run.Execute()
+		case <-time.After(time.Millisecond * 20):
+			return
+		}
+	}
+}
+
+// Function that returns a task printing the fake clock's time
+func report(clock Clock) func(Run) {
+	return func(run Run) {
+		fmt.Printf("[%s] %s (scheduled %s)\n", clock.Now().Format("15:04"), run.Name, run.Scheduled.Format("15:04"))
+	}
+}
+
+// Function that prints skipped runs
+func printSkip(name string, scheduled time.Time, reason string) {
+	fmt.Printf("Skipped %s at %s: %s\n", name, scheduled.Format("15:04"), reason)
+}
+
+func main() {
+	fmt.Println("Cron Scheduler in Go:")
+	start := time.Date(2026, time.January, 5, 9, 0, 0, 0, time.UTC) // A Monday
+
+	// Parsing cron expressions
+	fmt.Println("\n=== Parsing Schedules ===")
+	specs := []string{"*/15 * * * *", "0 9-17 * * mon-fri", "30 2 1 * *", "@hourly", "@every 90m", "61 * * * *"}
+	for _, spec := range specs {
+		schedule, err := ParseSchedule(spec)
+		if err != nil {
+			fmt.Println("Error:", err)
+			continue
+		}
+		t := start
+		fmt.Printf("%-20s", spec)
+		for i := 0; i < 3; i++ {
+			t = schedule.Next(t)
+			fmt.Print(" ", t.Format("Mon 01-02 15:04"))
+		}
+		fmt.Println()
+	}
+
+	// A fake clock makes the scheduler deterministic: time only moves
+	// when we say so
+	fmt.Println("\n=== Fake Clock ===")
+	clock := NewFakeClock(start)
+	runs := make(chan Run, 100)
+	scheduler := NewScheduler(runs, SchedulerOptions{Clock: clock, Rand: rand.New(rand.NewSource(1)), OnSkip: printSkip})
+	scheduler.Add("report", "*/15 * * * *", EntryOptions{}, report(clock))
+	scheduler.Add("sync", "@every 10m", EntryOptions{Jitter: 2 * time.Minute}, report(clock))
+	scheduler.Start()
+
+	clock.BlockUntil(1)
+	for step := 0; step < 30; step++ {
+		clock.Advance(time.Minute)
+		clock.BlockUntil(1) // The scheduler has reacted and set its next timer
+		drain(runs)
+	}
+	scheduler.Stop()
+
+	// A run is skipped while the previous one is still going
+	fmt.Println("\n=== Overlap Prevention ===")
+	clock = NewFakeClock(start)
+	scheduler = NewScheduler(runs, SchedulerOptions{Clock: clock, OnSkip: printSkip})
+	scheduler.Add("backup", "*/5 * * * *", EntryOptions{}, report(clock))
+	scheduler.Start()
+
+	clock.BlockUntil(1)
+	clock.Advance(5 * time.Minute)
+	clock.BlockUntil(1)
+	held := <-runs // Taken by a worker but not finished yet
+	fmt.Println("Backup started at 09:05 and is still running")
+
+	clock.Advance(5 * time.Minute)
+	clock.BlockUntil(1)
+	held.Execute()
+
+	clock.Advance(5 * time.Minute)
+	clock.BlockUntil(1)
+	drain(runs)
+	scheduler.Stop()
+
+	// The machine sleeps for three and a half hours
+	fmt.Println("\n=== Missed Runs ===")
+	policies := []struct {
+		name   string
+		policy MissedPolicy
+	}{
+		{"skip", MissedSkip},
+		{"run-once", MissedRunOnce},
+		{"run-all", MissedRunAll},
+	}
+	for _, p := range policies {
+		clock = NewFakeClock(start)
+		scheduler = NewScheduler(runs, SchedulerOptions{Clock: clock, OnSkip: printSkip})
+		scheduler.Add(p.name, "@hourly", EntryOptions{Missed: p.policy}, report(clock))
+		scheduler.Start()
+
+		clock.BlockUntil(1)
+		clock.Advance(3*time.Hour + 30*time.Minute)
+		clock.BlockUntil(1)
+		drain(runs)
+		scheduler.Stop()
+	}
+
+	// With the real clock, runs go to a pool of workers
+	fmt.Println("\n=== Real Clock with Worker Pool ===")
+	poolRuns := make(chan Run)
+	var wg sync.WaitGroup
+	for w := 1; w <= 2; w++ {
+		wg.Add(1)
+		go worker(w, poolRuns, &wg)
+	}
+
+	scheduler = NewScheduler(poolRuns, SchedulerOptions{OnSkip: printSkip})
+	ticks := 0
+	scheduler.Add("tick", "@every 100ms", EntryOptions{}, func(run Run) {
+		ticks++ // Safe: overlap prevention means only one tick runs at a time
+	})
+	scheduler.Start()
+	time.Sleep(time.Millisecond * 550)
+	scheduler.Stop()
+
+	close(poolRuns)
+	wg.Wait()
+	fmt.Println("Ticks:", ticks)
+
+	fmt.Println("\nCron scheduler examples completed")
+}
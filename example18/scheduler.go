@@ -0,0 +1,372 @@
+package main
+
+import (
+	"fmt"
+	"math/rand"
+	"sort"
+	"sync"
+	"time"
+)
+
+// MissedPolicy decides what happens to runs whose time passed while the
+// scheduler could not act, for example because the machine was asleep
+type MissedPolicy int
+
+const (
+	MissedRunOnce MissedPolicy = iota // Run once to catch up, however many were missed
+	MissedSkip                        // Drop missed runs and wait for the next one
+	MissedRunAll                      // Run every missed occurrence
+)
+
+// maxCatchUp bounds MissedRunAll after a very long gap
+const maxCatchUp = 100
+
+// EntryOptions controls how one recurring job is run
+type EntryOptions struct {
+	// Each run is delayed by a random amount below Jitter, so jobs with
+	// the same schedule do not all start at once
+	Jitter time.Duration
+	// Allow a new run to start while the previous one is still running
+	AllowOverlap bool
+	// What to do with runs that were missed
+	Missed MissedPolicy
+}
+
+// SchedulerOptions configures a Scheduler. All fields are optional.
+type SchedulerOptions struct {
+	Clock Clock      // Defaults to the real clock
+	Rand  *rand.Rand // Source of jitter
+	// A run handled more than this long after it was due counts as missed
+	LateTolerance time.Duration
+	// Called when a run is not submitted, with the reason. It is called
+	// from the scheduler goroutine, so it should not block.
+	OnSkip func(name string, scheduled time.Time, reason string)
+}
+
+// Run is one submission of a job into the worker pool
+type Run struct {
+	Name      string
+	Scheduled time.Time // When the run was due, before jitter
+	task      func(Run)
+	done      func()
+}
+
+// Execute runs the job; workers call this for every Run they receive
+func (r Run) Execute() {
+	defer r.done()
+	r.task(r)
+}
+
+// entry is one registered job
+type entry struct {
+	name     string
+	schedule Schedule
+	options  EntryOptions
+	task     func(Run)
+	next     time.Time // Next scheduled time; zero if it never runs again
+	fireAt   time.Time // next plus jitter
+	running  int
+	backlog  []time.Time // Catch-up runs waiting for the current run to finish
+}
+
+// Scheduler submits recurring jobs into a worker pool's channel at the
+// times their schedules give
+type Scheduler struct {
+	options SchedulerOptions
+	clock   Clock
+	runs    chan<- Run
+
+	mu      sync.Mutex
+	entries []*entry
+
+	wake chan struct{}
+	stop chan struct{}
+	done chan struct{}
+}
+
+// Constructor for the scheduler; runs is the worker pool's job channel
+func NewScheduler(runs chan<- Run, options SchedulerOptions) *Scheduler {
+	if options.Clock == nil {
+		options.Clock = realClock{}
+	}
+	if options.Rand == nil {
+		options.Rand = rand.New(rand.NewSource(time.Now().UnixNano()))
+	}
+	if options.LateTolerance == 0 {
+		options.LateTolerance = time.Second
+	}
+	return &Scheduler{
+		options: options,
+		clock:   options.Clock,
+		runs:    runs,
+		wake:    make(chan struct{}, 1),
+		stop:    make(chan struct{}),
+		done:    make(chan struct{}),
+	}
+}
+
+// Add registers a job under a cron expression or "@every" interval
+func (s *Scheduler) Add(name, spec string, options EntryOptions, task func(Run)) error {
+	schedule, err := ParseSchedule(spec)
+	if err != nil {
+		return fmt.Errorf("add %s: %w", name, err)
+	}
+
+	s.mu.Lock()
+	e := &entry{name: name, schedule: schedule, options: options, task: task}
+	s.planLocked(e, s.clock.Now())
+	s.entries = append(s.entries, e)
+	s.mu.Unlock()
+
+	// Let the loop recompute its timer
+	select {
+	case s.wake <- struct{}{}:
+	default:
+	}
+	return nil
+}
+
+// planLocked sets the entry's next run after t; s.mu must be held
+func (s *Scheduler) planLocked(e *entry, t time.Time) {
+	e.next = e.schedule.Next(t)
+	e.fireAt = e.next
+	if !e.next.IsZero() && e.options.Jitter > 0 {
+		e.fireAt = e.next.Add(time.Duration(s.options.Rand.Int63n(int64(e.options.Jitter))))
+	}
+}
+
+// Start runs the scheduler loop in a new goroutine
+func (s *Scheduler) Start() {
+	go s.loop()
+}
+
+// Stop ends the scheduler loop. Runs already submitted are not affected.
+func (s *Scheduler) Stop() {
+	close(s.stop)
+	<-s.done
+}
+
+// Scheduler loop: sleep until the earliest entry is due, then submit it
+func (s *Scheduler) loop() {
+	defer close(s.done)
+
+	for {
+		var timer Timer
+		var fired <-chan time.Time
+		if at, ok := s.earliest(); ok {
+			timer = s.clock.NewTimer(at.Sub(s.clock.Now()))
+			fired = timer.C()
+		}
+
+		select {
+		case <-fired:
+			s.runDue(s.clock.Now())
+		case <-s.wake:
+			s.runBacklog()
+		case <-s.stop:
+			if timer != nil {
+				timer.Stop()
+			}
+			return
+		}
+		if timer != nil {
+			timer.Stop()
+		}
+	}
+}
+
+// earliest returns the soonest fire time of any entry
+func (s *Scheduler) earliest() (time.Time, bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	var at time.Time
+	for _, e := range s.entries {
+		if e.fireAt.IsZero() {
+			continue
+		}
+		if at.IsZero() || e.fireAt.Before(at) {
+			at = e.fireAt
+		}
+	}
+	return at, !at.IsZero()
+}
+
+// skippedRun is a run that was not submitted, and why
+type skippedRun struct {
+	name      string
+	scheduled time.Time
+	reason    string
+}
+
+// runDue submits every entry that is due at now
+func (s *Scheduler) runDue(now time.Time) {
+	s.mu.Lock()
+	var runs []Run
+	var skipped []skippedRun
+	for _, e := range s.entries {
+		if e.fireAt.IsZero() || e.fireAt.After(now) {
+			continue
+		}
+		entryRuns, entrySkipped, last := s.dueRunsLocked(e, now)
+		runs = append(runs, entryRuns...)
+		skipped = append(skipped, entrySkipped...)
+		s.planLocked(e, last)
+	}
+	s.mu.Unlock()
+
+	if s.options.OnSkip != nil {
+		for _, sk := range skipped {
+			s.options.OnSkip(sk.name, sk.scheduled, sk.reason)
+		}
+	}
+
+	sort.SliceStable(runs, func(i, j int) bool { return runs[i].Scheduled.Before(runs[j].Scheduled) })
+	s.submit(runs)
+}
+
+// dueRunsLocked works out which of an entry's due occurrences to submit,
+// applying the missed-run and overlap rules. It also returns the time to
+// plan the next run from: the last occurrence, so "@every" intervals do
+// not drift. s.mu must be held.
+func (s *Scheduler) dueRunsLocked(e *entry, now time.Time) ([]Run, []skippedRun, time.Time) {
+	// Every occurrence up to now, in order
+	var onTime, missed []time.Time
+	last := e.next
+	for t := e.next; !t.IsZero() && !t.After(now); t = e.schedule.Next(t) {
+		last = t
+		// The first occurrence carries the jitter delay; later ones do
+		// not, and were only reached because we are late anyway
+		due := t
+		if t.Equal(e.next) {
+			due = e.fireAt
+		}
+		if now.Sub(due) > s.options.LateTolerance {
+			missed = append(missed, t)
+		} else {
+			onTime = append(onTime, t)
+		}
+		if len(missed)+len(onTime) >= maxCatchUp {
+			last = now // Give up on the rest of a very long gap
+			break
+		}
+	}
+
+	var skipped []skippedRun
+	skip := func(t time.Time, reason string) {
+		skipped = append(skipped, skippedRun{name: e.name, scheduled: t, reason: reason})
+	}
+
+	var scheduled []time.Time
+	switch e.options.Missed {
+	case MissedSkip:
+		for _, t := range missed {
+			skip(t, "missed")
+		}
+		scheduled = onTime
+	case MissedRunOnce:
+		switch {
+		case len(missed) == 0:
+			scheduled = onTime
+		case len(onTime) > 0:
+			// A run that is due now already catches up
+			for _, t := range missed {
+				skip(t, "missed, covered by the current run")
+			}
+			scheduled = onTime
+		default:
+			// One catch-up run stands in for all the missed ones
+			for _, t := range missed[:len(missed)-1] {
+				skip(t, "missed, coalesced into one run")
+			}
+			scheduled = missed[len(missed)-1:]
+		}
+	case MissedRunAll:
+		scheduled = append(missed, onTime...)
+	}
+
+	var runs []Run
+	if e.options.AllowOverlap {
+		for _, t := range scheduled {
+			e.running++
+			runs = append(runs, s.newRunLocked(e, t))
+		}
+		return runs, skipped, last
+	}
+
+	// Without overlap, catch-up runs wait in the backlog and are submitted
+	// one at a time as each finishes
+	if len(scheduled) == 0 {
+		return nil, skipped, last
+	}
+	if e.running > 0 {
+		for _, t := range scheduled {
+			skip(t, "previous run still running")
+		}
+		return nil, skipped, last
+	}
+	e.backlog = append(e.backlog, scheduled...)
+	if run, ok := s.nextBacklogLocked(e); ok {
+		runs = append(runs, run)
+	}
+	return runs, skipped, last
+}
+
+// newRunLocked builds the Run for one occurrence; s.mu must be held
+func (s *Scheduler) newRunLocked(e *entry, scheduled time.Time) Run {
+	var once sync.Once
+	done := func() {
+		once.Do(func() {
+			s.mu.Lock()
+			e.running--
+			hasBacklog := len(e.backlog) > 0
+			s.mu.Unlock()
+
+			// Let the loop submit the next catch-up run
+			if hasBacklog {
+				select {
+				case s.wake <- struct{}{}:
+				default:
+				}
+			}
+		})
+	}
+	return Run{Name: e.name, Scheduled: scheduled, task: e.task, done: done}
+}
+
+// nextBacklogLocked takes the next catch-up run if the entry is idle;
+// s.mu must be held
+func (s *Scheduler) nextBacklogLocked(e *entry) (Run, bool) {
+	if e.running > 0 || len(e.backlog) == 0 {
+		return Run{}, false
+	}
+	t := e.backlog[0]
+	e.backlog = e.backlog[1:]
+	e.running++
+	return s.newRunLocked(e, t), true
+}
+
+// runBacklog submits the next catch-up run of every idle entry
+func (s *Scheduler) runBacklog() {
+	s.mu.Lock()
+	var runs []Run
+	for _, e := range s.entries {
+		if run, ok := s.nextBacklogLocked(e); ok {
+			runs = append(runs, run)
+		}
+	}
+	s.mu.Unlock()
+
+	s.submit(runs)
+}
+
+// submit sends runs to the worker pool, giving up if the scheduler stops
+func (s *Scheduler) submit(runs []Run) {
+	for _, run := range runs {
+		select {
+		case s.runs <- run:
+		case <-s.stop:
+			return
+		}
+	}
+}
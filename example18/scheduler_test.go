@@ -0,0 +1,303 @@
+package main
+
+import (
+	"math/rand"
+	"testing"
+	"time"
+)
+
+// A Monday, so weekday schedules fire on the first day
+var testStart = time.Date(2026, time.January, 5, 9, 0, 0, 0, time.UTC)
+
+// skip is one OnSkip call
+type skip struct {
+	name      string
+	scheduled time.Time
+	reason    string
+}
+
+// harness runs a scheduler on a fake clock and records what it submits
+type harness struct {
+	t         *testing.T
+	clock     *FakeClock
+	runs      chan Run
+	scheduler *Scheduler
+	skips     []skip // Only read after the scheduler has reacted
+}
+
+func newHarness(t *testing.T) *harness {
+	h := &harness{t: t, clock: NewFakeClock(testStart), runs: make(chan Run, 1000)}
+	h.scheduler = NewScheduler(h.runs, SchedulerOptions{
+		Clock: h.clock,
+		Rand:  rand.New(rand.NewSource(1)),
+		OnSkip: func(name string, scheduled time.Time, reason string) {
+			h.skips = append(h.skips, skip{name, scheduled, reason})
+		},
+	})
+	return h
+}
+
+func (h *harness) add(name, spec string, options EntryOptions) {
+	h.t.Helper()
+	if err := h.scheduler.Add(name, spec, options, func(Run) {}); err != nil {
+		h.t.Fatal(err)
+	}
+}
+
+func (h *harness) start() {
+	h.scheduler.Start()
+	h.clock.BlockUntil(1)
+	h.t.Cleanup(h.scheduler.Stop)
+}
+
+// advance moves the clock and waits for the scheduler to set its next timer
+func (h *harness) advance(d time.Duration) {
+	h.clock.Advance(d)
+	h.clock.BlockUntil(1)
+}
+
+// submitted takes every run waiting in the channel without executing it
+func (h *harness) submitted() []Run {
+	var runs []Run
+	for {
+		select {
+		case run := <-h.runs:
+			runs = append(runs, run)
+		default:
+			return runs
+		}
+	}
+}
+
+// next waits for one run, failing the test if none arrives
+func (h *harness) next() Run {
+	h.t.Helper()
+	select {
+	case run := <-h.runs:
+		return run
+	case <-time.After(time.Second):
+		h.t.Fatal("no run submitted")
+		return Run{}
+	}
+}
+
+func at(hour, minute int) time.Time {
+	return time.Date(2026, time.January, 5, hour, minute, 0, 0, time.UTC)
+}
+
+func TestFireTimes(t *testing.T) {
+	h := newHarness(t)
+	h.add("report", "*/15 * * * *", EntryOptions{})
+	h.start()
+
+	// Step a minute at a time and note when each run is submitted
+	var fired, scheduled []time.Time
+	for step := 0; step < 60; step++ {
+		h.advance(time.Minute)
+		for _, run := range h.submitted() {
+			fired = append(fired, h.clock.Now())
+			scheduled = append(scheduled, run.Scheduled)
+			run.Execute()
+		}
+	}
+
+	want := []time.Time{at(9, 15), at(9, 30), at(9, 45), at(10, 0)}
+	if len(fired) != len(want) {
+		t.Fatalf("got %d runs, want %d: %v", len(fired), len(want), fired)
+	}
+	for i := range want {
+		if !fired[i].Equal(want[i]) || !scheduled[i].Equal(want[i]) {
+			t.Errorf("run %d fired at %v for %v, want %v", i, fired[i], scheduled[i], want[i])
+		}
+	}
+}
+
+func TestNothingFiresEarly(t *testing.T) {
+	h := newHarness(t)
+	h.add("daily", "30 9 * * *", EntryOptions{})
+	h.start()
+
+	h.advance(29*time.Minute + 59*time.Second)
+	if runs := h.submitted(); len(runs) != 0 {
+		t.Fatalf("run submitted a second early: %v", runs[0].Scheduled)
+	}
+	h.advance(time.Second)
+	runs := h.submitted()
+	if len(runs) != 1 || !runs[0].Scheduled.Equal(at(9, 30)) {
+		t.Fatalf("got %v, want one run at 09:30", runs)
+	}
+}
+
+func TestJitter(t *testing.T) {
+	const jitter = 2 * time.Minute
+	h := newHarness(t)
+	h.add("sync", "@every 10m", EntryOptions{Jitter: jitter})
+	h.start()
+
+	var count int
+	for step := 0; step < 60; step++ {
+		h.advance(time.Minute)
+		for _, run := range h.submitted() {
+			count++
+			// Jitter delays the run but not the schedule, so intervals
+			// do not drift
+			wantScheduled := testStart.Add(time.Duration(count) * 10 * time.Minute)
+			if !run.Scheduled.Equal(wantScheduled) {
+				t.Errorf("run %d scheduled %v, want %v", count, run.Scheduled, wantScheduled)
+			}
+			if delay := h.clock.Now().Sub(run.Scheduled); delay < 0 || delay > jitter {
+				t.Errorf("run %d fired %v after its time, want within %v", count, delay, jitter)
+			}
+			run.Execute()
+		}
+	}
+	if count != 5 && count != 6 {
+		t.Errorf("got %d runs in an hour of 10 minute intervals", count)
+	}
+}
+
+func TestOverlapPrevention(t *testing.T) {
+	h := newHarness(t)
+	h.add("backup", "*/5 * * * *", EntryOptions{})
+	h.start()
+
+	h.advance(5 * time.Minute)
+	held := h.next() // Started but not finished
+
+	h.advance(5 * time.Minute)
+	if runs := h.submitted(); len(runs) != 0 {
+		t.Fatalf("run at %v overlapped the one still running", runs[0].Scheduled)
+	}
+	if len(h.skips) != 1 || !h.skips[0].scheduled.Equal(at(9, 10)) {
+		t.Fatalf("skips = %v, want the 09:10 run", h.skips)
+	}
+	held.Execute()
+
+	h.advance(5 * time.Minute)
+	if run := h.next(); !run.Scheduled.Equal(at(9, 15)) {
+		t.Errorf("next run scheduled %v, want 09:15", run.Scheduled)
+	}
+}
+
+func TestAllowOverlap(t *testing.T) {
+	h := newHarness(t)
+	h.add("ping", "*/5 * * * *", EntryOptions{AllowOverlap: true})
+	h.start()
+
+	h.advance(5 * time.Minute)
+	h.next() // Never finishes
+	h.advance(5 * time.Minute)
+	if run := h.next(); !run.Scheduled.Equal(at(9, 10)) {
+		t.Errorf("overlapping run scheduled %v, want 09:10", run.Scheduled)
+	}
+	if len(h.skips) != 0 {
+		t.Errorf("unexpected skips %v", h.skips)
+	}
+}
+
+func TestMissedPolicies(t *testing.T) {
+	tests := []struct {
+		name   string
+		policy MissedPolicy
+		runs   []time.Time
+		skips  int
+	}{
+		{"skip", MissedSkip, nil, 3},
+		{"run-once", MissedRunOnce, []time.Time{at(12, 0)}, 2},
+		{"run-all", MissedRunAll, []time.Time{at(10, 0), at(11, 0), at(12, 0)}, 0},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			h := newHarness(t)
+			h.add(tt.name, "@hourly", EntryOptions{Missed: tt.policy})
+			h.start()
+
+			// The machine sleeps through three runs
+			h.advance(3*time.Hour + 30*time.Minute)
+
+			// Catch-up runs come one at a time, each after the last finishes
+			var got []time.Time
+			for range tt.runs {
+				run := h.next()
+				got = append(got, run.Scheduled)
+				run.Execute()
+			}
+			if runs := h.submitted(); len(runs) != 0 {
+				t.Fatalf("extra run scheduled %v", runs[0].Scheduled)
+			}
+			for i := range tt.runs {
+				if !got[i].Equal(tt.runs[i]) {
+					t.Errorf("run %d scheduled %v, want %v", i, got[i], tt.runs[i])
+				}
+			}
+			if len(h.skips) != tt.skips {
+				t.Errorf("got %d skips, want %d: %v", len(h.skips), tt.skips, h.skips)
+			}
+
+			// Afterwards the schedule carries on from the next occurrence
+			h.advance(30 * time.Minute)
+			if run := h.next(); !run.Scheduled.Equal(at(13, 0)) {
+				t.Errorf("run after catching up scheduled %v, want 13:00", run.Scheduled)
+			}
+		})
+	}
+}
+
+func TestRunsSubmittedInScheduledOrder(t *testing.T) {
+	h := newHarness(t)
+	h.add("half-hour", "@every 30m", EntryOptions{AllowOverlap: true, Missed: MissedRunAll})
+	h.add("twenty", "*/20 * * * *", EntryOptions{AllowOverlap: true, Missed: MissedRunAll})
+	h.start()
+
+	// Both entries become due during one jump of the clock
+	h.advance(time.Hour)
+	runs := h.submitted()
+
+	want := []struct {
+		name string
+		at   time.Time
+	}{
+		{"twenty", at(9, 20)},
+		{"half-hour", at(9, 30)},
+		{"twenty", at(9, 40)},
+		{"half-hour", at(10, 0)}, // Ties keep the order entries were added
+		{"twenty", at(10, 0)},
+	}
+	if len(runs) != len(want) {
+		t.Fatalf("got %d runs, want %d", len(runs), len(want))
+	}
+	for i, w := range want {
+		if runs[i].Name != w.name || !runs[i].Scheduled.Equal(w.at) {
+			t.Errorf("run %d = %s at %v, want %s at %v", i, runs[i].Name, runs[i].Scheduled, w.name, w.at)
+		}
+	}
+}
+
+func TestParseSchedule(t *testing.T) {
+	tests := []struct {
+		spec string
+		next time.Time
+	}{
+		{"*/15 * * * *", at(9, 15)},
+		{"0 9-17 * * mon-fri", at(10, 0)},
+		{"@hourly", at(10, 0)},
+		{"@every 90m", at(10, 30)},
+		{"30 2 1 * *", time.Date(2026, time.February, 1, 2, 30, 0, 0, time.UTC)},
+	}
+	for _, tt := range tests {
+		schedule, err := ParseSchedule(tt.spec)
+		if err != nil {
+			t.Errorf("%q: %v", tt.spec, err)
+			continue
+		}
+		if got := schedule.Next(testStart); !got.Equal(tt.next) {
+			t.Errorf("%q: next after %v = %v, want %v", tt.spec, testStart, got, tt.next)
+		}
+	}
+
+	for _, spec := range []string{"61 * * * *", "* * * *", "@every -1m", "*/0 * * * *", "5-1 * * * *"} {
+		if _, err := ParseSchedule(spec); err == nil {
+			t.Errorf("%q: expected an error", spec)
+		}
+	}
+}
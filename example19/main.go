@@ -0,0 +1,216 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"unicode"
+)
+
+// Example 19: MapReduce
+// Demonstrates a small in-process MapReduce engine built on goroutines and
+// channels, with a word count over files and a population rollup
+
+// Reads a whole file, as in example 10
+func readFile(path string) (string, error) {
+	// Open the file
+	file, err := os.Open(path)
+	if err != nil {
+		return "", fmt.Errorf("failed to open file: %w", err)
+	}
+	defer file.Close()
+
+	// Read the file content
+	var sb strings.Builder
+	buf := make([]byte, 1024)
+	for {
+		n, err := file.Read(buf)
+		if err != nil && err != io.EOF {
+			return "", fmt.Errorf("failed to read file: %w", err)
+		}
+
+		if n == 0 {
+			break
+		}
+
+		sb.Write(buf[:n])
+	}
+
+	return sb.String(), nil
+}
+
+// Sum is the reduce (and combine) function for both jobs
+func sum(key string, values []int64) int64 {
+	var total int64
+	for _, v := range values {
+		total += v
+	}
+	return total
+}
+
+// Word count: each record is a file path; every word counts once
+var wordCount = Job{
+	Name: "wordcount",
+	Map: func(path string, emit func(string, int64)) error {
+		content, err := readFile(path)
+		if err != nil {
+			return err
+		}
+		words := strings.FieldsFunc(strings.ToLower(content), func(r rune) bool {
+			return !unicode.IsLetter(r) && r != '\''
+		})
+		for _, word := range words {
+			// A token of only apostrophes, like a stray quote, is not a word
+			if word = strings.Trim(word, "'"); word != "" {
+				emit(word, 1)
+			}
+		}
+		return nil
+	},
+	Combine: sum,
+	Reduce:  sum,
+}
+
+// Population rollup: each record is "country<TAB>city<TAB>population"
+var populationByCountry = Job{
+	Name: "population",
+	Map: func(record string, emit func(string, int64)) error {
+		fields := strings.Split(record, "\t")
+		if len(fields) != 3 {
+			return fmt.Errorf("malformed record %q", record)
+		}
+		population, err := strconv.ParseInt(fields[2], 10, 64)
+		if err != nil {
+			return fmt.Errorf("bad population in %q: %w", record, err)
+		}
+		emit(fields[0], population)
+		return nil
+	},
+	Combine: sum,
+	Reduce:  sum,
+}
+
+// Function that writes the sample text files and returns their paths
+func writeSampleFiles(dir string) ([]string, error) {
+	texts := map[string]string{
+		"go.txt":       "Go is expressive, concise, clean, and efficient. Its concurrency mechanisms make it easy to write programs that get the most out of multicore and networked machines.",
+		"proverbs.txt": "Don't communicate by sharing memory, share memory by communicating. Concurrency is not parallelism. Channels orchestrate; mutexes serialize.",
+		"errors.txt":   "Errors are values. Don't just check errors, handle them gracefully. Don't panic. Make the zero value useful.",
+	}
+
+	var paths []string
+	for name, text := range texts {
+		path := filepath.Join(dir, name)
+		if err := os.WriteFile(path, []byte(strings.Repeat(text+"\n", 20)), 0o644); err != nil {
+			return nil, fmt.Errorf("failed to write sample file: %w", err)
+		}
+		paths = append(paths, path)
+	}
+	return paths, nil
+}
+
+// The nested city populations from example 6, including the cities added there
+var cityPopulations = map[string]map[string]int{
+	"USA": {
+		"New York":    8419000,
+		"Los Angeles": 3979576,
+		"Chicago":     2699000,
+		"Boston":      675647,
+	},
+	"Japan": {
+		"Tokyo": 9273000,
+		"Osaka": 2691000,
+		"Kyoto": 1459000,
+	},
+	"Canada": {
+		"Toronto":   2930000,
+		"Montreal":  1780000,
+		"Vancouver": 675218,
+	},
+}
+
+// Function that flattens the nested map into one record per city
+func cityRecords() []string {
+	var records []string
+	for country, cities := range cityPopulations {
+		for city, population := range cities {
+			records = append(records, fmt.Sprintf("%s\t%s\t%d", country, city, population))
+		}
+	}
+	return records
+}
+
+// Function that prints keys by descending value, then by name
+func printTop(output map[string]int64, n int) {
+	keys := make([]string, 0, len(output))
+	for k := range output {
+		keys = append(keys, k)
+	}
+	sort.Slice(keys, func(i, j int) bool {
+		if output[keys[i]] != output[keys[j]] {
+			return output[keys[i]] > output[keys[j]]
+		}
+		return keys[i] < keys[j]
+	})
+	if len(keys) > n {
+		keys = keys[:n]
+	}
+	for _, k := range keys {
+		fmt.Printf("  %-12s %d\n", k, output[k])
+	}
+}
+
+// Function that prints the engine's statistics
+func printStats(stats Stats) {
+	fmt.Printf("Records: %d, pairs emitted: %d, shuffled after combine: %d, keys: %d\n",
+		stats.Records, stats.Emitted, stats.Shuffled, stats.Keys)
+	fmt.Printf("Spills: %d (%d bytes)\n", stats.Spills, stats.SpillBytes)
+}
+
+func main() {
+	fmt.Println("MapReduce in Go:")
+	ctx := context.Background()
+
+	dir, err := os.MkdirTemp("", "example19")
+	if err != nil {
+		fmt.Println("Error:", err)
+		return
+	}
+	defer os.RemoveAll(dir)
+
+	// Word count with a tiny memory budget, so mappers spill to disk
+	fmt.Println("\n=== Word Count ===")
+	paths, err := writeSampleFiles(dir)
+	if err != nil {
+		fmt.Println("Error:", err)
+		return
+	}
+	counts, stats, err := Run(ctx, wordCount, paths, Config{Mappers: 3, Reducers: 4, SpillThreshold: 200})
+	if err != nil {
+		fmt.Println("Error:", err)
+		return
+	}
+	printTop(counts, 8)
+	printStats(stats)
+
+	// A missing file fails the whole job with a wrapped error
+	_, _, err = Run(ctx, wordCount, append(paths, filepath.Join(dir, "missing.txt")), Config{Mappers: 2})
+	fmt.Println("Missing file:", err)
+
+	// Population per country
+	fmt.Println("\n=== Population by Country ===")
+	totals, stats, err := Run(ctx, populationByCountry, cityRecords(), Config{Mappers: 2, Reducers: 2})
+	if err != nil {
+		fmt.Println("Error:", err)
+		return
+	}
+	printTop(totals, len(totals))
+	printStats(stats)
+
+	fmt.Println("\nMapReduce examples completed")
+}
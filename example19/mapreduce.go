@@ -0,0 +1,450 @@
+package main
+
+import (
+	"container/heap"
+	"context"
+	"encoding/gob"
+	"errors"
+	"fmt"
+	"hash/fnv"
+	"io"
+	"os"
+	"sort"
+	"sync"
+)
+
+// KeyValue is the pair that flows between the phases
+type KeyValue struct {
+	Key   string
+	Value int64
+}
+
+// MapFunc turns one input record into any number of pairs
+type MapFunc func(record string, emit func(key string, value int64)) error
+
+// ReduceFunc folds every value for a key into one
+type ReduceFunc func(key string, values []int64) int64
+
+// Job describes one MapReduce computation
+type Job struct {
+	Name   string
+	Map    MapFunc
+	Reduce ReduceFunc
+	// Combine pre-reduces each mapper's output before the shuffle. It is
+	// optional and must give the same answer however values are grouped,
+	// which is true for sums, counts, min and max.
+	Combine ReduceFunc
+	// Partition picks the reducer for a key; defaults to a hash
+	Partition func(key string, reducers int) int
+}
+
+// Config controls parallelism and memory use
+type Config struct {
+	Mappers  int
+	Reducers int
+	// Pairs a mapper holds in memory before spilling them to a temp file
+	SpillThreshold int
+	// Where spill files go; defaults to the system temp directory
+	TempDir string
+}
+
+// Stats reports what the engine did
+type Stats struct {
+	Records    int
+	Emitted    int
+	Shuffled   int // Pairs handed to reducers, after combining
+	Spills     int
+	SpillBytes int64
+	Keys       int
+}
+
+// sortedRun is one sorted batch of pairs for a partition, either held in
+// memory or spilled to a file
+type sortedRun struct {
+	pairs []KeyValue
+	path  string
+}
+
+// engine holds the state of one Run call
+type engine struct {
+	job     Job
+	config  Config
+	tempDir string
+
+	mu    sync.Mutex
+	runs  [][]sortedRun // By partition
+	stats Stats
+}
+
+// hashPartition is the default partitioner
+func hashPartition(key string, reducers int) int {
+	h := fnv.New32a()
+	h.Write([]byte(key))
+	return int(h.Sum32() % uint32(reducers))
+}
+
+// Run executes the job over the records: map, combine, partition and
+// shuffle, then reduce. It returns the reduced value for every key.
+func Run(ctx context.Context, job Job, records []string, config Config) (map[string]int64, Stats, error) {
+	if config.Mappers <= 0 {
+		config.Mappers = 1
+	}
+	if config.Reducers <= 0 {
+		config.Reducers = 1
+	}
+	if job.Partition == nil {
+		job.Partition = hashPartition
+	}
+
+	tempDir, err := os.MkdirTemp(config.TempDir, "mapreduce-"+job.Name)
+	if err != nil {
+		return nil, Stats{}, fmt.Errorf("failed to create spill directory: %w", err)
+	}
+	defer os.RemoveAll(tempDir)
+
+	e := &engine{
+		job:     job,
+		config:  config,
+		tempDir: tempDir,
+		runs:    make([][]sortedRun, config.Reducers),
+	}
+
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	// Map phase: mappers read records from a channel
+	inputs := make(chan string)
+	go func() {
+		defer close(inputs)
+		for _, record := range records {
+			select {
+			case inputs <- record:
+			case <-ctx.Done():
+				return
+			}
+		}
+	}()
+	if err := e.parallel(ctx, cancel, config.Mappers, func(id int) error {
+		return e.mapper(ctx, id, inputs)
+	}); err != nil {
+		return nil, e.stats, fmt.Errorf("%s map phase: %w", job.Name, err)
+	}
+
+	// Reduce phase: one reducer per partition, each merging its sorted runs
+	results := make([]map[string]int64, config.Reducers)
+	if err := e.parallel(ctx, cancel, config.Reducers, func(partition int) error {
+		var err error
+		results[partition], err = e.reducer(ctx, partition)
+		return err
+	}); err != nil {
+		return nil, e.stats, fmt.Errorf("%s reduce phase: %w", job.Name, err)
+	}
+
+	// Partitions never share keys, so the merge is a plain copy
+	output := make(map[string]int64)
+	for _, partial := range results {
+		for k, v := range partial {
+			output[k] = v
+		}
+	}
+	e.stats.Keys = len(output)
+	return output, e.stats, nil
+}
+
+// parallel runs fn(0..n-1) in goroutines, cancelling the rest on the
+// first error
+func (e *engine) parallel(ctx context.Context, cancel context.CancelFunc, n int, fn func(i int) error) error {
+	var wg sync.WaitGroup
+	var once sync.Once
+	var firstErr error
+
+	for i := 0; i < n; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			if err := fn(i); err != nil {
+				once.Do(func() {
+					firstErr = err
+					cancel()
+				})
+			}
+		}(i)
+	}
+	wg.Wait()
+
+	if firstErr == nil {
+		firstErr = ctx.Err()
+	}
+	return firstErr
+}
+
+// mapper applies the map function to records, buffering its output and
+// spilling to disk whenever the buffer passes the threshold
+func (e *engine) mapper(ctx context.Context, id int, inputs <-chan string) error {
+	buffer := make(map[string][]int64)
+	buffered, records, emitted := 0, 0, 0
+	emit := func(key string, value int64) {
+		buffer[key] = append(buffer[key], value)
+		buffered++
+		emitted++
+	}
+
+	for record := range inputs {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+		if err := e.job.Map(record, emit); err != nil {
+			return fmt.Errorf("mapper %d: %w", id, err)
+		}
+		records++
+
+		if e.config.SpillThreshold > 0 && buffered >= e.config.SpillThreshold {
+			if err := e.flush(buffer, true); err != nil {
+				return fmt.Errorf("mapper %d: %w", id, err)
+			}
+			buffer = make(map[string][]int64)
+			buffered = 0
+		}
+	}
+
+	// Whatever is left stays in memory for the reducers
+	if err := e.flush(buffer, false); err != nil {
+		return fmt.Errorf("mapper %d: %w", id, err)
+	}
+
+	e.mu.Lock()
+	e.stats.Records += records
+	e.stats.Emitted += emitted
+	e.mu.Unlock()
+	return nil
+}
+
+// flush combines a mapper's buffer, splits it by partition and sorts each
+// part by key, then keeps it in memory or writes it to spill files
+func (e *engine) flush(buffer map[string][]int64, spill bool) error {
+	parts := make([][]KeyValue, e.config.Reducers)
+	shuffled := 0
+	for key, values := range buffer {
+		p := e.job.Partition(key, e.config.Reducers)
+		if e.job.Combine != nil {
+			parts[p] = append(parts[p], KeyValue{key, e.job.Combine(key, values)})
+			shuffled++
+			continue
+		}
+		for _, v := range values {
+			parts[p] = append(parts[p], KeyValue{key, v})
+		}
+		shuffled += len(values)
+	}
+
+	for p, pairs := range parts {
+		if len(pairs) == 0 {
+			continue
+		}
+		sort.Slice(pairs, func(i, j int) bool { return pairs[i].Key < pairs[j].Key })
+
+		run := sortedRun{pairs: pairs}
+		if spill {
+			path, size, err := e.writeSpill(pairs)
+			if err != nil {
+				return err
+			}
+			run = sortedRun{path: path}
+
+			e.mu.Lock()
+			e.stats.SpillBytes += size
+			e.mu.Unlock()
+		}
+
+		e.mu.Lock()
+		e.runs[p] = append(e.runs[p], run)
+		e.mu.Unlock()
+	}
+
+	e.mu.Lock()
+	e.stats.Shuffled += shuffled
+	if spill {
+		e.stats.Spills++
+	}
+	e.mu.Unlock()
+	return nil
+}
+
+// writeSpill writes sorted pairs to a new temp file
+func (e *engine) writeSpill(pairs []KeyValue) (string, int64, error) {
+	file, err := os.CreateTemp(e.tempDir, "spill-*.gob")
+	if err != nil {
+		return "", 0, fmt.Errorf("failed to create spill file: %w", err)
+	}
+
+	enc := gob.NewEncoder(file)
+	for _, kv := range pairs {
+		if err := enc.Encode(kv); err != nil {
+			file.Close()
+			return "", 0, fmt.Errorf("failed to write spill file: %w", err)
+		}
+	}
+	size, err := file.Seek(0, io.SeekCurrent)
+	if err != nil {
+		file.Close()
+		return "", 0, fmt.Errorf("failed to size spill file: %w", err)
+	}
+	if err := file.Close(); err != nil {
+		return "", 0, fmt.Errorf("failed to close spill file: %w", err)
+	}
+	return file.Name(), size, nil
+}
+
+// reducer merges the sorted runs of one partition and reduces each key
+func (e *engine) reducer(ctx context.Context, partition int) (map[string]int64, error) {
+	var iters []pairIterator
+	defer func() {
+		for _, it := range iters {
+			it.Close()
+		}
+	}()
+	for _, run := range e.runs[partition] {
+		it, err := openRun(run)
+		if err != nil {
+			return nil, err
+		}
+		iters = append(iters, it)
+	}
+
+	merged, err := newMerger(iters)
+	if err != nil {
+		return nil, err
+	}
+
+	output := make(map[string]int64)
+	var key string
+	var values []int64
+	for {
+		if err := ctx.Err(); err != nil {
+			return nil, err
+		}
+		kv, ok, err := merged.Next()
+		if err != nil {
+			return nil, err
+		}
+		// Runs are sorted, so all values for a key arrive together
+		if len(values) > 0 && (!ok || kv.Key != key) {
+			output[key] = e.job.Reduce(key, values)
+			values = values[:0]
+		}
+		if !ok {
+			return output, nil
+		}
+		key = kv.Key
+		values = append(values, kv.Value)
+	}
+}
+
+// pairIterator reads one sorted run
+type pairIterator interface {
+	Next() (KeyValue, bool, error)
+	Close() error
+}
+
+// memoryIterator reads a run held in memory
+type memoryIterator struct {
+	pairs []KeyValue
+}
+
+func (it *memoryIterator) Next() (KeyValue, bool, error) {
+	if len(it.pairs) == 0 {
+		return KeyValue{}, false, nil
+	}
+	kv := it.pairs[0]
+	it.pairs = it.pairs[1:]
+	return kv, true, nil
+}
+
+func (it *memoryIterator) Close() error { return nil }
+
+// fileIterator streams a spilled run back from disk
+type fileIterator struct {
+	file *os.File
+	dec  *gob.Decoder
+}
+
+func (it *fileIterator) Next() (KeyValue, bool, error) {
+	var kv KeyValue
+	err := it.dec.Decode(&kv)
+	if errors.Is(err, io.EOF) {
+		return KeyValue{}, false, nil
+	}
+	if err != nil {
+		return KeyValue{}, false, fmt.Errorf("failed to read spill file: %w", err)
+	}
+	return kv, true, nil
+}
+
+func (it *fileIterator) Close() error { return it.file.Close() }
+
+// openRun returns an iterator over a run, wherever it is stored
+func openRun(run sortedRun) (pairIterator, error) {
+	if run.path == "" {
+		return &memoryIterator{pairs: run.pairs}, nil
+	}
+	file, err := os.Open(run.path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open spill file: %w", err)
+	}
+	return &fileIterator{file: file, dec: gob.NewDecoder(file)}, nil
+}
+
+// merger does a k-way merge of sorted iterators using a heap, so only one
+// pair per run is in memory at a time
+type merger struct {
+	heads []mergeHead
+}
+
+type mergeHead struct {
+	kv KeyValue
+	it pairIterator
+}
+
+func newMerger(iters []pairIterator) (*merger, error) {
+	m := &merger{}
+	for _, it := range iters {
+		kv, ok, err := it.Next()
+		if err != nil {
+			return nil, err
+		}
+		if ok {
+			m.heads = append(m.heads, mergeHead{kv, it})
+		}
+	}
+	heap.Init(m)
+	return m, nil
+}
+
+// Next returns the smallest remaining pair across all runs
+func (m *merger) Next() (KeyValue, bool, error) {
+	if len(m.heads) == 0 {
+		return KeyValue{}, false, nil
+	}
+	head := m.heads[0]
+	kv, ok, err := head.it.Next()
+	if err != nil {
+		return KeyValue{}, false, err
+	}
+	if ok {
+		m.heads[0].kv = kv
+		heap.Fix(m, 0)
+	} else {
+		heap.Pop(m)
+	}
+	return head.kv, true, nil
+}
+
+func (m *merger) Len() int           { return len(m.heads) }
+func (m *merger) Less(i, j int) bool { return m.heads[i].kv.Key < m.heads[j].kv.Key }
+func (m *merger) Swap(i, j int)      { m.heads[i], m.heads[j] = m.heads[j], m.heads[i] }
+func (m *merger) Push(x any)         { m.heads = append(m.heads, x.(mergeHead)) }
+func (m *merger) Pop() any {
+	last := m.heads[len(m.heads)-1]
+	m.heads = m.heads[:len(m.heads)-1]
+	return last
+}
@@ -0,0 +1,367 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+)
+
+// Actor owns its state and handles one message at a time. Nothing else can
+// reach the state, so it needs no locks.
+type Actor interface {
+	Receive(ctx *Context, msg any)
+}
+
+// ActorFunc lets a plain function be used as a stateless actor
+type ActorFunc func(ctx *Context, msg any)
+
+func (f ActorFunc) Receive(ctx *Context, msg any) { f(ctx, msg) }
+
+var (
+	// ErrStopped is returned when sending to an actor that has stopped, or
+	// spawning one on a supervisor that has
+	ErrStopped = errors.New("actor stopped")
+	// ErrCrashed is returned to an Ask whose message made the actor panic
+	ErrCrashed = errors.New("actor crashed")
+)
+
+// reply carries the answer to an Ask
+type reply struct {
+	value any
+	err   error
+}
+
+// envelope wraps a message with an optional reply channel
+type envelope struct {
+	msg   any
+	reply chan reply // Buffered; nil for Tell
+}
+
+// Context is passed to Receive for each message
+type Context struct {
+	Self    *Ref
+	env     envelope
+	replied bool
+}
+
+// Reply answers the current message if it was sent with Ask
+func (c *Context) Reply(value any) {
+	if c.env.reply == nil || c.replied {
+		return
+	}
+	c.replied = true
+	c.env.reply <- reply{value: value}
+}
+
+// Ref is the only handle other goroutines get to an actor. It stays valid
+// across restarts: the mailbox survives, only the state is replaced.
+type Ref struct {
+	name    string
+	mailbox chan envelope
+	stopped <-chan struct{} // Closed when the supervisor stops
+}
+
+// Name returns the actor's name
+func (r *Ref) Name() string { return r.name }
+
+// Tell sends a message without waiting for an answer
+func (r *Ref) Tell(msg any) error {
+	// Check first: select picks at random if the mailbox also has room
+	if r.isStopped() {
+		return fmt.Errorf("tell %s: %w", r.name, ErrStopped)
+	}
+	select {
+	case r.mailbox <- envelope{msg: msg}:
+		return nil
+	case <-r.stopped:
+		return fmt.Errorf("tell %s: %w", r.name, ErrStopped)
+	}
+}
+
+// Ask sends a message and waits for the reply, or until ctx is done
+func (r *Ref) Ask(ctx context.Context, msg any) (any, error) {
+	if r.isStopped() {
+		return nil, fmt.Errorf("ask %s: %w", r.name, ErrStopped)
+	}
+	env := envelope{msg: msg, reply: make(chan reply, 1)}
+	select {
+	case r.mailbox <- env:
+	case <-r.stopped:
+		return nil, fmt.Errorf("ask %s: %w", r.name, ErrStopped)
+	case <-ctx.Done():
+		return nil, fmt.Errorf("ask %s: %w", r.name, ctx.Err())
+	}
+
+	select {
+	case rep := <-env.reply:
+		return rep.value, rep.err
+	case <-r.stopped:
+		return nil, fmt.Errorf("ask %s: %w", r.name, ErrStopped)
+	case <-ctx.Done():
+		return nil, fmt.Errorf("ask %s: %w", r.name, ctx.Err())
+	}
+}
+
+// isStopped reports whether the supervisor has stopped
+func (r *Ref) isStopped() bool {
+	select {
+	case <-r.stopped:
+		return true
+	default:
+		return false
+	}
+}
+
+// Strategy decides which children restart when one crashes
+type Strategy int
+
+const (
+	OneForOne Strategy = iota // Restart only the child that crashed
+	OneForAll                 // Restart every child
+)
+
+func (s Strategy) String() string {
+	if s == OneForAll {
+		return "one-for-all"
+	}
+	return "one-for-one"
+}
+
+// child is a supervised actor and its current goroutine
+type child struct {
+	ref     *Ref
+	factory func() Actor
+	stop    chan struct{}
+	done    chan struct{}
+}
+
+// failure reports a crashed child to its supervisor
+type failure struct {
+	child *child
+	err   error
+}
+
+// Supervisor starts actors and restarts them when they crash. If children
+// crash more than MaxRestarts times within Window, it gives up and stops
+// them all.
+type Supervisor struct {
+	strategy    Strategy
+	maxRestarts int
+	window      time.Duration
+
+	mu       sync.Mutex
+	children []*child
+	restarts []time.Time
+	stopping bool // Set before the children are stopped for good; Spawn refuses after it
+
+	failures chan failure
+	quit     chan struct{}
+	stopped  chan struct{}
+	err      error
+}
+
+// Constructor for the supervisor; starts its goroutine
+func NewSupervisor(strategy Strategy, maxRestarts int, window time.Duration) *Supervisor {
+	s := &Supervisor{
+		strategy:    strategy,
+		maxRestarts: maxRestarts,
+		window:      window,
+		failures:    make(chan failure),
+		quit:        make(chan struct{}),
+		stopped:     make(chan struct{}),
+	}
+	go s.loop()
+	return s
+}
+
+// Spawn starts a supervised actor. The factory is called again for every
+// restart, so each restart begins with fresh state. It returns ErrStopped
+// once the supervisor is stopping.
+func (s *Supervisor) Spawn(name string, factory func() Actor) (*Ref, error) {
+	c := &child{
+		ref:     &Ref{name: name, mailbox: make(chan envelope, 64), stopped: s.stopped},
+		factory: factory,
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.stopping {
+		return nil, fmt.Errorf("spawn %s: %w", name, ErrStopped)
+	}
+	s.children = append(s.children, c)
+	s.start(c)
+	return c.ref, nil
+}
+
+// Stop stops every child and the supervisor
+func (s *Supervisor) Stop() {
+	select {
+	case s.quit <- struct{}{}:
+		<-s.stopped
+	case <-s.stopped: // Already stopped after giving up
+	}
+}
+
+// Done is closed once the supervisor has stopped
+func (s *Supervisor) Done() <-chan struct{} {
+	return s.stopped
+}
+
+// Err returns why the supervisor gave up, once Done is closed
+func (s *Supervisor) Err() error {
+	<-s.stopped
+	return s.err
+}
+
+// start launches a new goroutine, with new state, for a child
+func (s *Supervisor) start(c *child) {
+	c.stop = make(chan struct{})
+	c.done = make(chan struct{})
+	go s.run(c, c.factory(), c.stop, c.done)
+}
+
+// Mailbox loop: one goroutine per actor, handling one message at a time
+func (s *Supervisor) run(c *child, actor Actor, stop, done chan struct{}) {
+	defer close(done)
+
+	for {
+		select {
+		case <-stop:
+			return
+		case env := <-c.ref.mailbox:
+			if err := deliver(c.ref, actor, env); err != nil {
+				// Report the crash unless we are being stopped anyway
+				select {
+				case s.failures <- failure{child: c, err: err}:
+				case <-stop:
+				}
+				return
+			}
+		}
+	}
+}
+
+// deliver hands one message to the actor, turning a panic into an error
+func deliver(ref *Ref, actor Actor, env envelope) (err error) {
+	ctx := &Context{Self: ref, env: env}
+	defer func() {
+		if r := recover(); r != nil {
+			err = fmt.Errorf("actor %s panicked on %T: %v", ref.name, env.msg, r)
+			if env.reply != nil && !ctx.replied {
+				env.reply <- reply{err: fmt.Errorf("%w: %v", ErrCrashed, r)}
+			}
+		}
+	}()
+	actor.Receive(ctx, env.msg)
+	return nil
+}
+
+// Supervisor loop: restarts crashed children until told to quit
+func (s *Supervisor) loop() {
+	defer close(s.stopped)
+
+	for {
+		select {
+		case f := <-s.failures:
+			if err := s.handle(f); err != nil {
+				s.shutdown()
+				s.err = err
+				return
+			}
+		case <-s.quit:
+			s.shutdown()
+			return
+		}
+	}
+}
+
+// shutdown stops every child for good. Spawn is refused from here on, so
+// no child can start after the last one has been stopped.
+func (s *Supervisor) shutdown() {
+	s.mu.Lock()
+	s.stopping = true
+	s.mu.Unlock()
+	s.stopChildren(nil)
+}
+
+// handle applies the restart strategy to a crash
+func (s *Supervisor) handle(f failure) error {
+	fmt.Println("Supervisor:", f.err)
+
+	s.mu.Lock()
+	// Forget restarts that fell out of the window, then check the limit
+	now := time.Now()
+	recent := s.restarts[:0]
+	for _, t := range s.restarts {
+		if now.Sub(t) < s.window {
+			recent = append(recent, t)
+		}
+	}
+	s.restarts = append(recent, now)
+	if len(s.restarts) > s.maxRestarts {
+		s.mu.Unlock()
+		return fmt.Errorf("more than %d restarts in %v, giving up: %w", s.maxRestarts, s.window, f.err)
+	}
+	if s.strategy == OneForOne {
+		fmt.Printf("Supervisor: restarting %s\n", f.child.ref.name)
+		s.start(f.child)
+		s.mu.Unlock()
+		return nil
+	}
+	s.mu.Unlock()
+
+	// OneForAll: stop the others without holding the lock, then restart
+	// everything that was stopped, in spawn order
+	restart := map[*child]bool{f.child: true}
+	for _, c := range s.stopChildren(f.child) {
+		restart[c] = true
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for _, c := range s.children {
+		if restart[c] {
+			fmt.Printf("Supervisor: restarting %s\n", c.ref.name)
+			s.start(c)
+		}
+	}
+	return nil
+}
+
+// stopping is a child being stopped, with the channels of the run that
+// is being stopped
+type stopping struct {
+	child      *child
+	stop, done chan struct{}
+}
+
+// stopChildren stops every child except skip (which has already exited)
+// and returns the children it stopped. Each child finishes the message it
+// is handling; queued messages stay in the mailbox. It waits without
+// holding s.mu, because an actor may call Spawn while finishing its
+// message; children spawned that way are stopped too.
+func (s *Supervisor) stopChildren(skip *child) []*child {
+	seen := map[*child]bool{skip: true}
+	var stopped []*child
+	for {
+		s.mu.Lock()
+		var batch []stopping
+		for _, c := range s.children {
+			if !seen[c] {
+				seen[c] = true
+				batch = append(batch, stopping{child: c, stop: c.stop, done: c.done})
+			}
+		}
+		s.mu.Unlock()
+
+		if len(batch) == 0 {
+			return stopped
+		}
+		for _, st := range batch {
+			close(st.stop)
+			<-st.done
+			stopped = append(stopped, st.child)
+		}
+	}
+}
@@ -0,0 +1,186 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+)
+
+// Example 20: Actors
+// Demonstrates the actor model: each actor owns its state and handles
+// messages from its mailbox one at a time, and supervisors restart actors
+// that crash
+
+// Messages understood by the counter actor
+type Increment struct{}
+type Get struct{}
+type Crash struct{}
+
+// counter is an actor that replaces the counter and mutex in example 9's
+// mutexExample. Only its own goroutine ever touches count.
+type counter struct {
+	count int
+}
+
+func (c *counter) Receive(ctx *Context, msg any) {
+	switch msg.(type) {
+	case Increment:
+		c.count++
+	case Get:
+		ctx.Reply(c.count)
+	case Crash:
+		panic("asked to crash")
+	}
+}
+
+// Function that builds a fresh counter for each (re)start
+func newCounter() Actor {
+	return &counter{}
+}
+
+// Function that asks a counter for its value with a timeout
+func getCount(ref *Ref) (int, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), time.Millisecond*100)
+	defer cancel()
+
+	value, err := ref.Ask(ctx, Get{})
+	if err != nil {
+		return 0, err
+	}
+	return value.(int), nil
+}
+
+// Function that prints the value of each counter
+func printCounts(refs ...*Ref) {
+	for _, ref := range refs {
+		count, err := getCount(ref)
+		if err != nil {
+			fmt.Printf("%s: error %v\n", ref.Name(), err)
+			continue
+		}
+		fmt.Printf("%s: %d\n", ref.Name(), count)
+	}
+}
+
+// Like mutexExample, but the goroutines send messages instead of locking
+func actorExample() {
+	supervisor := NewSupervisor(OneForOne, 3, time.Second)
+	defer supervisor.Stop()
+	ref, err := supervisor.Spawn("counter", newCounter)
+	if err != nil {
+		fmt.Println("Error:", err)
+		return
+	}
+
+	var wg sync.WaitGroup
+	for i := 0; i < 10; i++ {
+		wg.Add(1)
+		go func(id int) {
+			defer wg.Done()
+			for j := 0; j < 1000; j++ {
+				ref.Tell(Increment{})
+			}
+			fmt.Printf("Goroutine %d completed\n", id)
+		}(i)
+	}
+	wg.Wait()
+
+	// Every Tell has returned, so the increments are queued ahead of this
+	// Get and the mailbox handles them in order
+	printCounts(ref)
+}
+
+// Function that spawns the two counters used by the supervision demos
+func spawnPair(supervisor *Supervisor) (*Ref, *Ref, error) {
+	a, err := supervisor.Spawn("counter-a", newCounter)
+	if err != nil {
+		return nil, nil, err
+	}
+	b, err := supervisor.Spawn("counter-b", newCounter)
+	if err != nil {
+		return nil, nil, err
+	}
+	return a, b, nil
+}
+
+func main() {
+	fmt.Println("Actors in Go:")
+
+	fmt.Println("\n=== Counter Actor ===")
+	actorExample()
+
+	// Request/reply with a timeout
+	fmt.Println("\n=== Ask with Timeout ===")
+	supervisor := NewSupervisor(OneForOne, 3, time.Second)
+	slow, err := supervisor.Spawn("slow", func() Actor {
+		return ActorFunc(func(ctx *Context, msg any) {
+			time.Sleep(time.Millisecond * 200)
+			ctx.Reply("done")
+		})
+	})
+	if err != nil {
+		fmt.Println("Error:", err)
+		return
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), time.Millisecond*50)
+	_, err = slow.Ask(ctx, "work")
+	cancel()
+	fmt.Println("Slow reply:", err)
+	fmt.Println("Timed out:", errors.Is(err, context.DeadlineExceeded))
+	supervisor.Stop()
+
+	// One-for-one: only the crashed actor restarts, with fresh state
+	fmt.Println("\n=== One-for-One Supervision ===")
+	supervisor = NewSupervisor(OneForOne, 3, time.Second)
+	a, b, err := spawnPair(supervisor)
+	if err != nil {
+		fmt.Println("Error:", err)
+		return
+	}
+	for i := 0; i < 5; i++ {
+		a.Tell(Increment{})
+		b.Tell(Increment{})
+	}
+	_, err = a.Ask(context.Background(), Crash{})
+	fmt.Println("Crash reply:", err)
+	printCounts(a, b)
+	supervisor.Stop()
+
+	// One-for-all: every actor restarts when one crashes
+	fmt.Println("\n=== One-for-All Supervision ===")
+	supervisor = NewSupervisor(OneForAll, 3, time.Second)
+	a, b, err = spawnPair(supervisor)
+	if err != nil {
+		fmt.Println("Error:", err)
+		return
+	}
+	for i := 0; i < 5; i++ {
+		a.Tell(Increment{})
+		b.Tell(Increment{})
+	}
+	a.Tell(Crash{})
+	time.Sleep(time.Millisecond * 20) // Let the restart happen
+	printCounts(a, b)
+	supervisor.Stop()
+
+	// Too many crashes: the supervisor gives up
+	fmt.Println("\n=== Restart Limit ===")
+	supervisor = NewSupervisor(OneForOne, 2, time.Second)
+	a, err = supervisor.Spawn("counter-a", newCounter)
+	if err != nil {
+		fmt.Println("Error:", err)
+		return
+	}
+	for i := 0; i < 3; i++ {
+		a.Tell(Crash{})
+	}
+	<-supervisor.Done()
+	fmt.Println("Supervisor stopped:", supervisor.Err())
+	fmt.Println("Tell after stop:", a.Tell(Increment{}))
+	_, err = supervisor.Spawn("counter-b", newCounter)
+	fmt.Println("Spawn after stop:", err)
+
+	fmt.Println("\nActor examples completed")
+}
@@ -0,0 +1,189 @@
+package main
+
+import (
+	"bufio"
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"os"
+	"os/signal"
+	"path/filepath"
+	"sync"
+	"syscall"
+	"time"
+)
+
+// Example 21: Graceful Shutdown
+// Demonstrates shutting down a pipeline and worker pool on SIGINT or
+// SIGTERM: stop taking new work, drain what is in flight within a deadline,
+// flush the results and exit with a clear status. A second signal forces
+// an immediate exit.
+//
+// By default the program interrupts itself after a second. Run it with
+// -signal-after 0 and press Ctrl-C instead (twice to force).
+
+// Exit statuses
+const (
+	exitClean       = 0   // Everything drained and flushed
+	exitDeadline    = 1   // The drain deadline passed and work was abandoned
+	exitFlushFailed = 3   // Results could not be written; 2 is taken by flag errors
+	exitForced      = 130 // A second signal arrived (128 + SIGINT)
+)
+
+// Pipeline stage that produces numbers until stop is cancelled
+func generateNumbers(stop context.Context, out chan<- int) {
+	defer close(out) // Closing tells the next stage no more is coming
+	for i := 1; ; i++ {
+		select {
+		case out <- i:
+			time.Sleep(time.Millisecond * 20)
+		case <-stop.Done():
+			fmt.Printf("Generator stopped after %d numbers\n", i-1)
+			return
+		}
+	}
+}
+
+// Pipeline stage that squares numbers and submits them to the pool. It
+// drains its input even after shutdown starts, so nothing already produced
+// is lost.
+func squareNumbers(in <-chan int, pool *Pool) {
+	for num := range in {
+		if err := pool.Submit(num * num); err != nil {
+			fmt.Println("Dropped:", err)
+		}
+	}
+}
+
+// Simulated work that stops early if its context is cancelled
+func process(jobTime time.Duration) func(ctx context.Context, job int) (int, error) {
+	return func(ctx context.Context, job int) (int, error) {
+		select {
+		case <-time.After(jobTime):
+			return job * 2, nil
+		case <-ctx.Done():
+			return 0, ctx.Err()
+		}
+	}
+}
+
+// Function that writes results through a buffer, which must be flushed
+// before exit or the tail of the output is lost
+func writeResults(path string, results <-chan Result) (written, abandoned int, err error) {
+	file, err := os.Create(path)
+	if err != nil {
+		// Keep draining, or the pool blocks sending results nobody reads
+		for range results {
+			abandoned++
+		}
+		return 0, abandoned, fmt.Errorf("failed to create results file: %w", err)
+	}
+	defer file.Close()
+
+	w := bufio.NewWriter(file)
+	for result := range results {
+		if result.Err != nil {
+			abandoned++
+			continue
+		}
+		fmt.Fprintf(w, "%d\t%d\n", result.Job, result.Value)
+		written++
+	}
+
+	if err := w.Flush(); err != nil {
+		return written, abandoned, fmt.Errorf("failed to flush results: %w", err)
+	}
+	return written, abandoned, file.Sync()
+}
+
+func main() {
+	deadline := flag.Duration("deadline", 2*time.Second, "how long to wait for in-flight work after a signal")
+	jobTime := flag.Duration("job-time", 100*time.Millisecond, "how long each job takes")
+	signalAfter := flag.Duration("signal-after", time.Second, "send ourselves SIGINT after this long (0 = wait for a real signal)")
+	out := flag.String("out", filepath.Join(os.TempDir(), "example21-results.txt"), "results file")
+	flag.Parse()
+
+	fmt.Println("Graceful Shutdown in Go:")
+
+	// Listen for signals. The first one stops intake; the second forces exit.
+	signals := make(chan os.Signal, 2)
+	signal.Notify(signals, os.Interrupt, syscall.SIGTERM)
+	stop, stopIntake := context.WithCancel(context.Background())
+	go func() {
+		sig := <-signals
+		fmt.Printf("\nReceived %v: no new work, draining for up to %v (signal again to force)\n", sig, *deadline)
+		stopIntake()
+
+		sig = <-signals
+		fmt.Printf("Received %v again: exiting immediately, results not flushed\n", sig)
+		os.Exit(exitForced)
+	}()
+
+	if *signalAfter > 0 {
+		time.AfterFunc(*signalAfter, func() {
+			self, _ := os.FindProcess(os.Getpid())
+			self.Signal(os.Interrupt)
+		})
+	}
+
+	// generator -> squarer -> pool -> results file
+	pool := NewPool(3, 10, process(*jobTime))
+	numbers := make(chan int)
+	var pipeline sync.WaitGroup
+	pipeline.Add(2)
+	go func() {
+		defer pipeline.Done()
+		generateNumbers(stop, numbers)
+	}()
+	go func() {
+		defer pipeline.Done()
+		squareNumbers(numbers, pool)
+	}()
+
+	type summary struct {
+		written, abandoned int
+		err                error
+	}
+	flushed := make(chan summary)
+	go func() {
+		written, abandoned, err := writeResults(*out, pool.Results())
+		flushed <- summary{written, abandoned, err}
+	}()
+
+	// Run until a signal stops the intake
+	<-stop.Done()
+	start := time.Now()
+	ctx, cancel := context.WithTimeout(context.Background(), *deadline)
+	defer cancel()
+
+	// Let the upstream stages hand everything to the pool, then drain it.
+	// Both share the one deadline.
+	pipelineDone := make(chan struct{})
+	go func() {
+		pipeline.Wait()
+		close(pipelineDone)
+	}()
+	select {
+	case <-pipelineDone:
+	case <-ctx.Done():
+	}
+	shutdownErr := pool.Shutdown(ctx)
+	result := <-flushed
+	fmt.Printf("Drained in %v\n", time.Since(start).Round(time.Millisecond))
+
+	// A failed write loses results that were computed, so it outranks a
+	// missed deadline
+	code := exitClean
+	if errors.Is(shutdownErr, context.DeadlineExceeded) {
+		fmt.Printf("Shutdown incomplete: %v, %d jobs abandoned\n", shutdownErr, result.abandoned)
+		code = exitDeadline
+	}
+	if result.err != nil {
+		fmt.Println("Error:", result.err)
+		code = exitFlushFailed
+	}
+	fmt.Printf("Flushed %d results to %s\n", result.written, *out)
+	fmt.Println("Exit status:", code)
+	os.Exit(code)
+}
@@ -0,0 +1,113 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+)
+
+// ErrPoolClosed is returned by Submit once shutdown has started
+var ErrPoolClosed = errors.New("pool is shutting down")
+
+// Result of one job
+type Result struct {
+	Job   int
+	Value int
+	Err   error
+}
+
+// Pool is a worker pool that can be shut down gracefully: it stops taking
+// jobs, finishes the ones it has, and only cuts work short if a deadline
+// passes
+type Pool struct {
+	process func(ctx context.Context, job int) (int, error)
+	jobs    chan int
+	results chan Result
+	wg      sync.WaitGroup
+
+	// jobCtx is given to every job and cancelled only when the drain
+	// deadline passes
+	jobCtx     context.Context
+	cancelJobs context.CancelFunc
+
+	mu     sync.RWMutex
+	closed bool
+}
+
+// Constructor for the pool; starts the workers
+func NewPool(numWorkers, queueSize int, process func(ctx context.Context, job int) (int, error)) *Pool {
+	jobCtx, cancelJobs := context.WithCancel(context.Background())
+	p := &Pool{
+		process:    process,
+		jobs:       make(chan int, queueSize),
+		results:    make(chan Result, queueSize),
+		jobCtx:     jobCtx,
+		cancelJobs: cancelJobs,
+	}
+	for w := 1; w <= numWorkers; w++ {
+		p.wg.Add(1)
+		go p.worker(w)
+	}
+	return p
+}
+
+// Worker loop: keeps going until the job queue is closed and empty
+func (p *Pool) worker(id int) {
+	defer p.wg.Done()
+
+	for job := range p.jobs {
+		value, err := p.process(p.jobCtx, job)
+		p.results <- Result{Job: job, Value: value, Err: err}
+	}
+}
+
+// Submit queues a job, or fails once shutdown has started
+func (p *Pool) Submit(job int) error {
+	// The read lock keeps Shutdown from closing the channel mid-send
+	p.mu.RLock()
+	defer p.mu.RUnlock()
+
+	if p.closed {
+		return ErrPoolClosed
+	}
+	select {
+	case p.jobs <- job:
+		return nil
+	case <-p.jobCtx.Done():
+		return ErrPoolClosed // The drain deadline passed while we waited
+	}
+}
+
+// Results returns the results channel; it is closed after shutdown
+func (p *Pool) Results() <-chan Result {
+	return p.results
+}
+
+// Shutdown stops accepting jobs and waits for queued and running jobs to
+// finish. If ctx ends first, running jobs are cancelled, the rest of the
+// queue is abandoned, and the context's error is returned.
+func (p *Pool) Shutdown(ctx context.Context) error {
+	// When ctx ends, cancel running jobs and any Submit still waiting for
+	// queue space, which lets the lock below be taken
+	stopCancelling := context.AfterFunc(ctx, p.cancelJobs)
+	defer stopCancelling()
+
+	p.mu.Lock()
+	if !p.closed {
+		p.closed = true
+		close(p.jobs)
+	}
+	p.mu.Unlock()
+
+	// Once jobs are cancelled the workers race through the rest of the
+	// queue, so this wait is short even after the deadline
+	p.wg.Wait()
+	close(p.results)
+
+	if err := ctx.Err(); err != nil {
+		return fmt.Errorf("drain deadline exceeded: %w", err)
+	}
+	p.cancelJobs()
+	return nil
+}
@@ -0,0 +1,194 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"time"
+)
+
+// Example 22: Metrics Endpoint
+// Demonstrates exposing worker pool, pipeline and mutex metrics over HTTP
+// in the Prometheus text format, using only the standard library
+
+// Metrics for the worker pool
+type poolMetrics struct {
+	processed *Counter
+	latency   *Histogram
+	active    *Gauge
+}
+
+// Function that registers the worker pool metrics
+func newPoolMetrics(registry *Registry, jobs chan int) *poolMetrics {
+	labels := Labels{"pool": "example"}
+	registry.NewGaugeFunc("pool_queue_depth", "Jobs waiting in the queue.", labels, func() float64 {
+		return float64(len(jobs))
+	})
+	return &poolMetrics{
+		processed: registry.NewCounter("pool_jobs_processed_total", "Jobs completed by the workers.", labels),
+		latency:   registry.NewHistogram("pool_job_duration_seconds", "Time spent processing each job.", labels, DefaultBuckets),
+		active:    registry.NewGauge("pool_active_workers", "Workers currently processing a job.", labels),
+	}
+}
+
+// Worker pool pattern, recording metrics for every job
+func worker(id int, jobs <-chan int, results chan<- int, wg *sync.WaitGroup, m *poolMetrics) {
+	defer wg.Done()
+
+	for job := range jobs {
+		m.active.Inc()
+		start := time.Now()
+		time.Sleep(time.Millisecond * time.Duration(10*job)) // Simulate work
+		results <- job * 2
+		m.latency.Observe(time.Since(start).Seconds())
+		m.processed.Inc()
+		m.active.Dec()
+	}
+}
+
+// Pipeline stage that sends numbers to a channel
+func generateNumbers(count int, ch chan<- int, items *Counter) {
+	for i := 1; i <= count; i++ {
+		ch <- i
+		items.Inc()
+	}
+	close(ch)
+}
+
+// Pipeline stage that squares numbers
+func squareNumbers(in <-chan int, out chan<- int, items *Counter) {
+	for num := range in {
+		time.Sleep(time.Millisecond * 5) // Slower than the generator
+		out <- num * num
+		items.Inc()
+	}
+	close(out)
+}
+
+// InstrumentedMutex is a sync.Mutex that records how long Lock waited
+type InstrumentedMutex struct {
+	mu   sync.Mutex
+	wait *Histogram
+}
+
+func (m *InstrumentedMutex) Lock() {
+	start := time.Now()
+	m.mu.Lock()
+	m.wait.Observe(time.Since(start).Seconds())
+}
+
+func (m *InstrumentedMutex) Unlock() {
+	m.mu.Unlock()
+}
+
+// Function that runs the pipeline and mutex workloads once and sends a
+// batch of jobs through the already running worker pool
+func runWorkload(jobs chan<- int, results <-chan int, pipelineItems map[string]*Counter, mutex *InstrumentedMutex) {
+	// Worker pool
+	for j := 1; j <= 10; j++ {
+		jobs <- j
+	}
+
+	// Pipeline
+	numbers := make(chan int, 10)
+	squares := make(chan int, 10)
+	go generateNumbers(20, numbers, pipelineItems["generate"])
+	go squareNumbers(numbers, squares, pipelineItems["square"])
+	for range squares {
+		pipelineItems["collect"].Inc()
+	}
+
+	// Mutex, as in example 9's mutexExample
+	counter := 0
+	var mutexWg sync.WaitGroup
+	for i := 0; i < 10; i++ {
+		mutexWg.Add(1)
+		go func() {
+			defer mutexWg.Done()
+			for j := 0; j < 100; j++ {
+				mutex.Lock()
+				counter++
+				mutex.Unlock()
+			}
+		}()
+	}
+	mutexWg.Wait()
+
+	// Collect the pool's results
+	for j := 0; j < 10; j++ {
+		<-results
+	}
+}
+
+func main() {
+	listen := flag.String("listen", "", "serve /metrics on this address (e.g. localhost:9090) and keep running")
+	flag.Parse()
+
+	fmt.Println("Metrics Endpoint in Go:")
+
+	registry := NewRegistry()
+	jobs := make(chan int, 10)
+	poolMetrics := newPoolMetrics(registry, jobs)
+	pipelineItems := make(map[string]*Counter)
+	for _, stage := range []string{"generate", "square", "collect"} {
+		pipelineItems[stage] = registry.NewCounter("pipeline_items_total", "Items that passed through each pipeline stage.", Labels{"stage": stage})
+	}
+	mutex := &InstrumentedMutex{
+		wait: registry.NewHistogram("mutex_wait_seconds", "Time spent waiting to acquire the counter mutex.", Labels{"mutex": "counter"},
+			[]float64{.00001, .0001, .001, .01, .1}),
+	}
+
+	// Start the workers once; every workload run reuses them
+	results := make(chan int, cap(jobs))
+	var wg sync.WaitGroup
+	for w := 1; w <= 3; w++ {
+		wg.Add(1)
+		go worker(w, jobs, results, &wg, poolMetrics)
+	}
+
+	if *listen != "" {
+		// Serve for real and keep generating activity to watch
+		http.Handle("/metrics", registry.Handler())
+		go func() {
+			for {
+				runWorkload(jobs, results, pipelineItems, mutex)
+				time.Sleep(time.Second)
+			}
+		}()
+		fmt.Printf("Serving metrics on http://%s/metrics\n", *listen)
+		if err := http.ListenAndServe(*listen, nil); err != nil {
+			fmt.Println("Error:", err)
+		}
+		return
+	}
+
+	runWorkload(jobs, results, pipelineItems, mutex)
+	close(jobs)
+	wg.Wait()
+
+	// httptest starts a real HTTP server on a random local port, which is
+	// also how the handler would be tested
+	server := httptest.NewServer(registry.Handler())
+	defer server.Close()
+
+	resp, err := http.Get(server.URL + "/metrics")
+	if err != nil {
+		fmt.Println("Error:", err)
+		return
+	}
+	defer resp.Body.Close()
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		fmt.Println("Error:", err)
+		return
+	}
+
+	fmt.Println("\n=== GET /metrics ===")
+	fmt.Println("Content-Type:", resp.Header.Get("Content-Type"))
+	fmt.Print(string(body))
+
+	fmt.Println("\nMetrics examples completed")
+}
@@ -0,0 +1,145 @@
+package main
+
+import (
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+// Function that scrapes a registry through its handler
+func scrape(t *testing.T, r *Registry) string {
+	t.Helper()
+	rec := httptest.NewRecorder()
+	r.Handler().ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/metrics", nil))
+
+	if rec.Code != http.StatusOK {
+		t.Fatalf("status %d", rec.Code)
+	}
+	if ct := rec.Header().Get("Content-Type"); !strings.HasPrefix(ct, "text/plain; version=0.0.4") {
+		t.Errorf("Content-Type = %q", ct)
+	}
+	return rec.Body.String()
+}
+
+// Function that checks body is exactly the given lines
+func expectLines(t *testing.T, body string, want ...string) {
+	t.Helper()
+	got := strings.Split(strings.TrimSuffix(body, "\n"), "\n")
+	if strings.Join(got, "\n") != strings.Join(want, "\n") {
+		t.Errorf("got:\n%s\nwant:\n%s", strings.Join(got, "\n"), strings.Join(want, "\n"))
+	}
+}
+
+func TestHelpAndType(t *testing.T) {
+	r := NewRegistry()
+	r.NewGauge("queue_depth", "Jobs waiting.", nil).Set(3)
+	c := r.NewCounter("jobs_total", "Jobs processed,\nby worker. Path C:\\jobs", Labels{"worker": "1"})
+	c.Add(2.5)
+	r.NewCounter("jobs_total", "ignored: the first help wins", Labels{"worker": "2"}).Inc()
+	r.NewGaugeFunc("workers", "Running workers.", nil, func() float64 { return 4 })
+
+	// Families sorted by name, series by labels, and help escaped
+	expectLines(t, scrape(t, r),
+		`# HELP jobs_total Jobs processed,\nby worker. Path C:\\jobs`,
+		`# TYPE jobs_total counter`,
+		`jobs_total{worker="1"} 2.5`,
+		`jobs_total{worker="2"} 1`,
+		`# HELP queue_depth Jobs waiting.`,
+		`# TYPE queue_depth gauge`,
+		`queue_depth 3`,
+		`# HELP workers Running workers.`,
+		`# TYPE workers gauge`,
+		`workers 4`,
+	)
+}
+
+func TestLabelEscaping(t *testing.T) {
+	r := NewRegistry()
+	r.NewCounter("errors_total", "Errors.", Labels{
+		"path":  `C:\tmp`,
+		"msg":   `say "hi"` + "\nbye",
+		"stage": "square",
+	}).Inc()
+
+	// Labels sorted by name; backslash, quote and newline escaped
+	expectLines(t, scrape(t, r),
+		`# HELP errors_total Errors.`,
+		`# TYPE errors_total counter`,
+		`errors_total{msg="say \"hi\"\nbye",path="C:\\tmp",stage="square"} 1`,
+	)
+}
+
+func TestHistogramBuckets(t *testing.T) {
+	r := NewRegistry()
+	h := r.NewHistogram("job_seconds", "Job latency.", Labels{"pool": "a"}, []float64{1, 0.25, 0.5})
+	for _, v := range []float64{0.125, 0.25, 0.375, 0.75, 2} {
+		h.Observe(v)
+	}
+
+	// Bounds sorted, buckets cumulative and inclusive, +Inf equal to count
+	expectLines(t, scrape(t, r),
+		`# HELP job_seconds Job latency.`,
+		`# TYPE job_seconds histogram`,
+		`job_seconds_bucket{pool="a",le="0.25"} 2`,
+		`job_seconds_bucket{pool="a",le="0.5"} 3`,
+		`job_seconds_bucket{pool="a",le="1"} 4`,
+		`job_seconds_bucket{pool="a",le="+Inf"} 5`,
+		`job_seconds_sum{pool="a"} 3.5`,
+		`job_seconds_count{pool="a"} 5`,
+	)
+}
+
+func TestHistogramWithoutLabels(t *testing.T) {
+	r := NewRegistry()
+	r.NewHistogram("wait_seconds", "Wait.", nil, []float64{1}).Observe(0.5)
+
+	expectLines(t, scrape(t, r),
+		`# HELP wait_seconds Wait.`,
+		`# TYPE wait_seconds histogram`,
+		`wait_seconds_bucket{le="1"} 1`,
+		`wait_seconds_bucket{le="+Inf"} 1`,
+		`wait_seconds_sum 0.5`,
+		`wait_seconds_count 1`,
+	)
+}
+
+func TestServer(t *testing.T) {
+	r := NewRegistry()
+	r.NewCounter("requests_total", "Requests.", nil).Add(7)
+
+	server := httptest.NewServer(r.Handler())
+	defer server.Close()
+
+	resp, err := http.Get(server.URL + "/metrics")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer resp.Body.Close()
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(string(body), "\nrequests_total 7\n") {
+		t.Errorf("sample missing from:\n%s", body)
+	}
+}
+
+func TestRegisterConflicts(t *testing.T) {
+	expectPanic := func(name string, fn func()) {
+		t.Helper()
+		defer func() {
+			if recover() == nil {
+				t.Errorf("%s: expected a panic", name)
+			}
+		}()
+		fn()
+	}
+
+	r := NewRegistry()
+	r.NewCounter("jobs", "Jobs.", Labels{"a": "1"})
+	expectPanic("same labels twice", func() { r.NewCounter("jobs", "Jobs.", Labels{"a": "1"}) })
+	expectPanic("different type", func() { r.NewGauge("jobs", "Jobs.", Labels{"a": "2"}) })
+	expectPanic("negative counter", func() { r.NewCounter("other", "Other.", nil).Add(-1) })
+}
@@ -0,0 +1,193 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+)
+
+// ErrNoFutures is the result of Any or Race called without any futures:
+// neither has anything that could ever finish
+var ErrNoFutures = errors.New("no futures to wait for")
+
+// Future holds the result of an asynchronous call. The result is stored
+// once and then read by any number of waiters; the goroutine producing it
+// never blocks on a send, so it cannot leak waiting for a reader.
+type Future[T any] struct {
+	done   chan struct{}
+	value  T
+	err    error
+	cancel context.CancelFunc
+}
+
+// Async runs fn in a new goroutine and returns its future. fn gets a
+// context that is cancelled by Cancel, or when a combinator no longer needs
+// the result.
+func Async[T any](ctx context.Context, fn func(ctx context.Context) (T, error)) *Future[T] {
+	ctx, cancel := context.WithCancel(ctx)
+	f := &Future[T]{done: make(chan struct{}), cancel: cancel}
+	go func() {
+		defer cancel()
+		f.value, f.err = fn(ctx)
+		close(f.done) // Publishes value and err to every reader
+	}()
+	return f
+}
+
+// Resolved returns a future that already holds a value
+func Resolved[T any](value T) *Future[T] {
+	f := &Future[T]{done: make(chan struct{}), value: value, cancel: func() {}}
+	close(f.done)
+	return f
+}
+
+// Rejected returns a future that has already failed with err
+func Rejected[T any](err error) *Future[T] {
+	f := &Future[T]{done: make(chan struct{}), err: err, cancel: func() {}}
+	close(f.done)
+	return f
+}
+
+// Await waits for the result. If ctx ends first it returns ctx's error;
+// the future itself keeps running.
+func (f *Future[T]) Await(ctx context.Context) (T, error) {
+	select {
+	case <-f.done:
+		return f.value, f.err
+	case <-ctx.Done():
+		var zero T
+		return zero, ctx.Err()
+	}
+}
+
+// Done is closed once the result is available
+func (f *Future[T]) Done() <-chan struct{} {
+	return f.done
+}
+
+// Cancel asks the computation to stop by cancelling its context
+func (f *Future[T]) Cancel() {
+	f.cancel()
+}
+
+// settled sends the index of each future as it completes. The channel is
+// buffered so the helper goroutines never block, even if nobody reads.
+func settled[T any](futures []*Future[T]) <-chan int {
+	ch := make(chan int, len(futures))
+	for i, f := range futures {
+		go func(i int, f *Future[T]) {
+			<-f.done
+			ch <- i
+		}(i, f)
+	}
+	return ch
+}
+
+// cancelAll cancels every future; cancelling a finished one does nothing
+func cancelAll[T any](futures []*Future[T]) {
+	for _, f := range futures {
+		f.Cancel()
+	}
+}
+
+// All waits for every future and returns their values in order. The first
+// error fails the whole result and cancels the rest.
+func All[T any](futures ...*Future[T]) *Future[[]T] {
+	return Async(context.Background(), func(ctx context.Context) ([]T, error) {
+		ready := settled(futures)
+		values := make([]T, len(futures))
+		for range futures {
+			select {
+			case i := <-ready:
+				if err := futures[i].err; err != nil {
+					cancelAll(futures)
+					return nil, err
+				}
+				values[i] = futures[i].value
+			case <-ctx.Done():
+				cancelAll(futures)
+				return nil, ctx.Err()
+			}
+		}
+		return values, nil
+	})
+}
+
+// Any returns the first successful value and cancels the rest. It fails
+// only if every future fails, with all their errors joined, or at once
+// with ErrNoFutures if there are none.
+func Any[T any](futures ...*Future[T]) *Future[T] {
+	if len(futures) == 0 {
+		return Rejected[T](ErrNoFutures)
+	}
+	return Async(context.Background(), func(ctx context.Context) (T, error) {
+		ready := settled(futures)
+		var errs []error
+		for range futures {
+			select {
+			case i := <-ready:
+				if err := futures[i].err; err != nil {
+					errs = append(errs, err)
+					continue
+				}
+				cancelAll(futures)
+				return futures[i].value, nil
+			case <-ctx.Done():
+				cancelAll(futures)
+				var zero T
+				return zero, ctx.Err()
+			}
+		}
+		var zero T
+		return zero, fmt.Errorf("all %d futures failed: %w", len(futures), errors.Join(errs...))
+	})
+}
+
+// Race returns the result of whichever future finishes first, success or
+// failure, and cancels the rest. With no futures it fails at once with
+// ErrNoFutures.
+func Race[T any](futures ...*Future[T]) *Future[T] {
+	if len(futures) == 0 {
+		return Rejected[T](ErrNoFutures)
+	}
+	return Async(context.Background(), func(ctx context.Context) (T, error) {
+		defer cancelAll(futures)
+		select {
+		case i := <-settled(futures):
+			return futures[i].value, futures[i].err
+		case <-ctx.Done():
+			var zero T
+			return zero, ctx.Err()
+		}
+	})
+}
+
+// Then runs fn on the value of f once it succeeds. Errors from f are
+// passed through without calling fn.
+func Then[T, U any](f *Future[T], fn func(ctx context.Context, value T) (U, error)) *Future[U] {
+	return Async(context.Background(), func(ctx context.Context) (U, error) {
+		value, err := f.Await(ctx)
+		if err != nil {
+			f.Cancel()
+			var zero U
+			return zero, err
+		}
+		return fn(ctx, value)
+	})
+}
+
+// WithTimeout fails with context.DeadlineExceeded if f has not finished
+// within d, and cancels f
+func WithTimeout[T any](f *Future[T], d time.Duration) *Future[T] {
+	return Async(context.Background(), func(ctx context.Context) (T, error) {
+		ctx, cancel := context.WithTimeout(ctx, d)
+		defer cancel()
+
+		value, err := f.Await(ctx)
+		if err != nil && ctx.Err() != nil {
+			f.Cancel()
+		}
+		return value, err
+	})
+}
@@ -0,0 +1,104 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"runtime"
+	"strings"
+	"time"
+)
+
+// Example 23: Futures
+// Demonstrates futures and combinators as a higher-level alternative to
+// hand-written channels and select statements
+
+// Function that simulates a remote call, stopping early if cancelled
+func fetch(name string, delay time.Duration, fail bool) func(context.Context) (string, error) {
+	return func(ctx context.Context) (string, error) {
+		select {
+		case <-time.After(delay):
+			if fail {
+				return "", fmt.Errorf("%s failed", name)
+			}
+			return name, nil
+		case <-ctx.Done():
+			fmt.Printf("  %s cancelled\n", name)
+			return "", ctx.Err()
+		}
+	}
+}
+
+func main() {
+	fmt.Println("Futures in Go:")
+	ctx := context.Background()
+	goroutinesBefore := runtime.NumGoroutine()
+
+	// The same two channels as selectExample in example 9, as futures
+	fmt.Println("\n=== Await ===")
+	ch1 := Async(ctx, fetch("Channel 1", time.Millisecond*20, false))
+	ch2 := Async(ctx, fetch("Channel 2", time.Millisecond*10, false))
+	msg, _ := ch2.Await(ctx)
+	fmt.Println("Received:", msg)
+	msg, _ = ch1.Await(ctx)
+	fmt.Println("Received:", msg)
+
+	// All: every value, in order
+	fmt.Println("\n=== All ===")
+	all, err := All(
+		Async(ctx, fetch("users", time.Millisecond*20, false)),
+		Async(ctx, fetch("orders", time.Millisecond*10, false)),
+		Async(ctx, fetch("stock", time.Millisecond*15, false)),
+	).Await(ctx)
+	fmt.Println("Values:", all, "Error:", err)
+
+	_, err = All(
+		Async(ctx, fetch("users", time.Millisecond*50, false)),
+		Async(ctx, fetch("orders", time.Millisecond*10, true)),
+	).Await(ctx)
+	fmt.Println("Error:", err)
+
+	// Any: first success wins, failures are ignored unless all fail
+	fmt.Println("\n=== Any ===")
+	value, err := Any(
+		Async(ctx, fetch("primary", time.Millisecond*5, true)),
+		Async(ctx, fetch("replica-1", time.Millisecond*20, false)),
+		Async(ctx, fetch("replica-2", time.Millisecond*50, false)),
+	).Await(ctx)
+	fmt.Println("Value:", value, "Error:", err)
+
+	// Race: first to finish wins, even if it failed
+	fmt.Println("\n=== Race ===")
+	value, err = Race(
+		Async(ctx, fetch("Channel 1", time.Millisecond*20, false)),
+		Async(ctx, fetch("Channel 2", time.Millisecond*10, false)),
+	).Await(ctx)
+	fmt.Println("Winner:", value, "Error:", err)
+
+	// With nothing to wait for, both fail at once instead of hanging
+	_, err = Race[string]().Await(ctx)
+	fmt.Println("Empty race:", err)
+	_, err = Any[string]().Await(ctx)
+	fmt.Println("Empty any:", err)
+
+	// Then: chain a step onto a future
+	fmt.Println("\n=== Then ===")
+	shout := Then(Async(ctx, fetch("hello", time.Millisecond*5, false)), func(ctx context.Context, s string) (int, error) {
+		return len(strings.ToUpper(s) + "!"), nil
+	})
+	length, err := shout.Await(ctx)
+	fmt.Println("Length:", length, "Error:", err)
+
+	// WithTimeout: the same as the time.After case in selectExample
+	fmt.Println("\n=== WithTimeout ===")
+	_, err = WithTimeout(Async(ctx, fetch("slow", time.Second, false)), time.Millisecond*30).Await(ctx)
+	fmt.Println("Error:", err)
+	fmt.Println("Timed out:", errors.Is(err, context.DeadlineExceeded))
+
+	// Losing branches were cancelled, so their goroutines are gone
+	fmt.Println("\n=== Goroutine Leaks ===")
+	time.Sleep(time.Millisecond * 20)
+	fmt.Println("Goroutines before:", goroutinesBefore, "after:", runtime.NumGoroutine())
+
+	fmt.Println("\nFuture examples completed")
+}
@@ -0,0 +1,145 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+)
+
+// Example 24: Channel Multiplexer
+// Demonstrates fanning in from a set of channels that changes at runtime,
+// using reflect.Select instead of a fixed select statement
+
+// Function that produces count values at the given interval, then closes
+func producer(name string, count int, interval time.Duration) <-chan string {
+	ch := make(chan string)
+	go func() {
+		defer close(ch)
+		for i := 1; i <= count; i++ {
+			time.Sleep(interval)
+			ch <- fmt.Sprintf("%s #%d", name, i)
+		}
+	}()
+	return ch
+}
+
+// Function that produces values as fast as they are read until stop closes
+func flood(name string, stop <-chan struct{}) <-chan int {
+	ch := make(chan int)
+	go func() {
+		defer close(ch)
+		for i := 0; ; i++ {
+			select {
+			case ch <- i:
+			case <-stop:
+				return
+			}
+		}
+	}()
+	return ch
+}
+
+func main() {
+	fmt.Println("Channel Multiplexer in Go:")
+
+	// The two channels from selectExample in example 9, added at runtime
+	fmt.Println("\n=== Tagged Sources ===")
+	mux := NewMux[string](0)
+	mux.Add("ch1", producer("Channel 1", 1, time.Millisecond*20))
+	mux.Add("ch2", producer("Channel 2", 1, time.Millisecond*10))
+	for i := 0; i < 2; i++ {
+		msg := <-mux.Out()
+		fmt.Printf("Received from %s: %s\n", msg.Source, msg.Value)
+	}
+	time.Sleep(time.Millisecond * 5)
+	fmt.Println("Sources after both closed:", mux.Sources())
+	mux.Close()
+
+	// Producers are discovered while the multiplexer runs
+	fmt.Println("\n=== Dynamic Add and Remove ===")
+	mux = NewMux[string](0)
+	go func() {
+		for i := 1; i <= 3; i++ {
+			name := fmt.Sprintf("sensor-%d", i)
+			if err := mux.Add(name, producer(name, 5, time.Millisecond*10)); err != nil {
+				fmt.Println("Error:", err)
+			}
+			time.Sleep(time.Millisecond * 15)
+		}
+	}()
+	time.Sleep(time.Millisecond * 5)
+	if err := mux.Add("sensor-1", producer("duplicate", 1, 0)); err != nil {
+		fmt.Println("Error:", err)
+	}
+
+	counts := make(map[string]int)
+	timeout := time.After(time.Millisecond * 200)
+	removed := false
+loop:
+	for {
+		select {
+		case msg, ok := <-mux.Out():
+			if !ok {
+				break loop
+			}
+			counts[msg.Source]++
+			fmt.Printf("  [%s] %s\n", msg.Source, msg.Value)
+
+			// Stop listening to sensor-2 after its second reading
+			if msg.Source == "sensor-2" && counts[msg.Source] == 2 && !removed {
+				removed = true
+				if err := mux.Remove("sensor-2"); err != nil {
+					fmt.Println("Error:", err)
+				}
+				fmt.Println("  removed sensor-2, sources:", mux.Sources())
+			}
+		case <-timeout:
+			break loop
+		}
+	}
+	if err := mux.Remove("sensor-9"); err != nil {
+		fmt.Println("Error:", err)
+	}
+	mux.Close()
+	fmt.Println("Readings per source:", counts)
+
+	// Sources that are always ready get equal shares: each round, every
+	// ready source delivers once before any source delivers again
+	fmt.Println("\n=== Fairness ===")
+	stop := make(chan struct{})
+	numbers := NewMux[int](0)
+	numbers.Add("a", flood("a", stop))
+	numbers.Add("b", flood("b", stop))
+	numbers.Add("c", flood("c", stop))
+
+	var order []string
+	totals := make(map[string]int)
+	for i := 0; i < 3000; i++ {
+		msg := <-numbers.Out()
+		totals[msg.Source]++
+		if i < 9 {
+			order = append(order, msg.Source)
+		}
+	}
+	close(stop)
+	numbers.Close()
+
+	fmt.Println("First deliveries:", strings.Join(order, " "))
+	names := make([]string, 0, len(totals))
+	for name := range totals {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		fmt.Printf("  %-6s %d\n", name, totals[name])
+	}
+
+	// Changes after Close fail
+	fmt.Println("\n=== Closed Multiplexer ===")
+	err := numbers.Add("late", flood("late", stop))
+	fmt.Println("Error:", err, "closed:", errors.Is(err, ErrMuxClosed))
+
+	fmt.Println("\nChannel multiplexer examples completed")
+}
@@ -0,0 +1,254 @@
+package main
+
+import (
+	"errors"
+	"reflect"
+	"sort"
+	"sync"
+)
+
+// ErrDuplicateSource is returned when adding a source name that is already in use
+var ErrDuplicateSource = errors.New("source already added")
+
+// ErrUnknownSource is returned when removing a source that was never added
+// or has already gone
+var ErrUnknownSource = errors.New("no such source")
+
+// ErrMuxClosed is returned when changing a closed multiplexer
+var ErrMuxClosed = errors.New("multiplexer closed")
+
+// Message is a value tagged with the source it came from
+type Message[T any] struct {
+	Source string
+	Value  T
+}
+
+// source is one input channel, stored as a reflect value for reflect.Select
+type source struct {
+	name string
+	ch   reflect.Value
+}
+
+// opKind is what a muxOp asks the loop to do
+type opKind int
+
+const (
+	opAdd opKind = iota
+	opRemove
+	opList
+)
+
+// muxOp is a request to the loop goroutine, which owns the set of sources
+type muxOp struct {
+	kind   opKind
+	name   string
+	ch     reflect.Value
+	result chan error
+	names  chan []string
+}
+
+// Mux merges a set of channels that can change while it runs into one
+// output channel. It is fair: a source never delivers twice while another
+// source has a value ready and has not yet delivered.
+type Mux[T any] struct {
+	out      chan Message[T]
+	ops      chan muxOp
+	stop     chan struct{}
+	stopOnce sync.Once // Close can be called more than once, concurrently
+	done     chan struct{}
+
+	sources []source
+	served  map[string]bool // Sources that delivered in the current round
+}
+
+// Constructor for the multiplexer; buffer is the size of the output channel
+func NewMux[T any](buffer int) *Mux[T] {
+	m := &Mux[T]{
+		out:    make(chan Message[T], buffer),
+		ops:    make(chan muxOp),
+		stop:   make(chan struct{}),
+		done:   make(chan struct{}),
+		served: make(map[string]bool),
+	}
+	go m.loop()
+	return m
+}
+
+// Out returns the merged channel. It is closed after Close.
+func (m *Mux[T]) Out() <-chan Message[T] {
+	return m.out
+}
+
+// Add starts reading from ch, tagging its values with name. A source is
+// removed automatically when its channel is closed.
+func (m *Mux[T]) Add(name string, ch <-chan T) error {
+	return m.apply(muxOp{kind: opAdd, name: name, ch: reflect.ValueOf(ch)})
+}
+
+// Remove stops reading from the named source. Values already received
+// from it are still delivered.
+func (m *Mux[T]) Remove(name string) error {
+	return m.apply(muxOp{kind: opRemove, name: name})
+}
+
+// Sources returns the names of the current sources, sorted
+func (m *Mux[T]) Sources() []string {
+	op := muxOp{kind: opList, names: make(chan []string, 1)}
+	select {
+	case m.ops <- op:
+		return <-op.names
+	case <-m.done:
+		return nil
+	}
+}
+
+// apply hands a change to the loop goroutine and waits for its result
+func (m *Mux[T]) apply(op muxOp) error {
+	op.result = make(chan error, 1)
+	select {
+	case m.ops <- op:
+		return <-op.result
+	case <-m.done:
+		return ErrMuxClosed
+	}
+}
+
+// Close stops the multiplexer and closes the output channel. The source
+// channels are left alone; they belong to their producers.
+func (m *Mux[T]) Close() {
+	m.stopOnce.Do(func() { close(m.stop) })
+	<-m.done
+}
+
+// Multiplexer loop: receive from a ready source, then deliver the tagged value
+func (m *Mux[T]) loop() {
+	defer close(m.done)
+	defer close(m.out)
+
+	for {
+		msg, ok := m.receive()
+		if !ok {
+			return
+		}
+
+		// Keep handling changes while the reader is slow
+		for sent := false; !sent; {
+			select {
+			case m.out <- msg:
+				sent = true
+			case op := <-m.ops:
+				m.handle(op)
+			case <-m.stop:
+				return
+			}
+		}
+	}
+}
+
+// selectResult is the outcome of one selectSources call
+type selectResult int
+
+const (
+	selectValue   selectResult = iota // A source delivered a value
+	selectHandled                     // A request or a closed source was handled
+	selectIdle                        // No remaining source was ready
+	selectStopped                     // Close was called
+)
+
+// receive waits for the next value. Sources that already delivered in this
+// round are left out while any other source is ready; when none is, a new
+// round starts. It returns false once the multiplexer is stopped.
+func (m *Mux[T]) receive() (Message[T], bool) {
+	for {
+		// Only block when a fresh round starts with every source eligible
+		msg, result := m.selectSources(len(m.served) == 0)
+		switch result {
+		case selectValue:
+			return msg, true
+		case selectStopped:
+			return Message[T]{}, false
+		case selectIdle:
+			m.served = make(map[string]bool)
+		}
+	}
+}
+
+// selectSources runs one reflect.Select over the stop and request channels
+// and every source not yet served in this round
+func (m *Mux[T]) selectSources(block bool) (Message[T], selectResult) {
+	cases := []reflect.SelectCase{
+		{Dir: reflect.SelectRecv, Chan: reflect.ValueOf(m.stop)},
+		{Dir: reflect.SelectRecv, Chan: reflect.ValueOf(m.ops)},
+	}
+	var candidates []source
+	for _, s := range m.sources {
+		if m.served[s.name] {
+			continue
+		}
+		candidates = append(candidates, s)
+		cases = append(cases, reflect.SelectCase{Dir: reflect.SelectRecv, Chan: s.ch})
+	}
+	if !block {
+		cases = append(cases, reflect.SelectCase{Dir: reflect.SelectDefault})
+	}
+
+	chosen, value, received := reflect.Select(cases)
+	switch {
+	case chosen == 0:
+		return Message[T]{}, selectStopped
+	case chosen == 1:
+		m.handle(value.Interface().(muxOp))
+		return Message[T]{}, selectHandled
+	case !block && chosen == len(cases)-1:
+		return Message[T]{}, selectIdle
+	}
+
+	s := candidates[chosen-2]
+	if !received {
+		// The producer closed its channel
+		m.removeSource(s.name)
+		return Message[T]{}, selectHandled
+	}
+	m.served[s.name] = true
+	return Message[T]{Source: s.name, Value: value.Interface().(T)}, selectValue
+}
+
+// handle applies one request from Add, Remove or Sources
+func (m *Mux[T]) handle(op muxOp) {
+	switch op.kind {
+	case opAdd:
+		for _, s := range m.sources {
+			if s.name == op.name {
+				op.result <- ErrDuplicateSource
+				return
+			}
+		}
+		m.sources = append(m.sources, source{name: op.name, ch: op.ch})
+		op.result <- nil
+	case opRemove:
+		if !m.removeSource(op.name) {
+			op.result <- ErrUnknownSource
+			return
+		}
+		op.result <- nil
+	case opList:
+		names := make([]string, 0, len(m.sources))
+		for _, s := range m.sources {
+			names = append(names, s.name)
+		}
+		sort.Strings(names)
+		op.names <- names
+	}
+}
+
+// removeSource drops a source by name, reporting whether it existed
+func (m *Mux[T]) removeSource(name string) bool {
+	for i, s := range m.sources {
+		if s.name == name {
+			m.sources = append(m.sources[:i], m.sources[i+1:]...)
+			delete(m.served, name)
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,140 @@
+package main
+
+import (
+	"fmt"
+	"math/rand"
+	"time"
+)
+
+// Example 25: Stream Windows
+// Demonstrates tumbling, sliding and session windows over timestamped
+// channel streams, with count, sum, min/max and percentile aggregates
+
+// Function that sends numbers to a channel, as in example 9
+func generateNumbers(count int, ch chan<- int) {
+	for i := 1; i <= count; i++ {
+		ch <- i
+	}
+	close(ch)
+}
+
+// Function that returns a clock advancing by step on every call, so the
+// demo's event times do not depend on how fast it runs
+func steppingClock(start time.Time, step time.Duration) func() time.Time {
+	now := start.Add(-step)
+	return func() time.Time {
+		now = now.Add(step)
+		return now
+	}
+}
+
+// Function that sends events at the given offsets from start
+func eventsAt[T any](start time.Time, offsets []time.Duration, values []T) <-chan Event[T] {
+	out := make(chan Event[T])
+	go func() {
+		defer close(out)
+		for i, offset := range offsets {
+			out <- Event[T]{Time: start.Add(offset), Value: values[i]}
+		}
+	}()
+	return out
+}
+
+func main() {
+	fmt.Println("Stream Windows in Go:")
+	start := time.Date(2024, 1, 1, 12, 0, 0, 0, time.UTC)
+
+	// Numbers arrive every 250ms; each 1s window holds four
+	fmt.Println("\n=== Tumbling Windows ===")
+	numbers := make(chan int)
+	go generateNumbers(10, numbers)
+	events := Stamp(numbers, steppingClock(start, time.Millisecond*250))
+	windows, err := Tumbling(events, time.Second)
+	if err != nil {
+		fmt.Println("Error:", err)
+		return
+	}
+	for stats := range Aggregate(windows, Summarize[int]) {
+		fmt.Println(stats)
+	}
+
+	// 1s windows every 500ms, so each number is counted twice
+	fmt.Println("\n=== Sliding Windows ===")
+	numbers = make(chan int)
+	go generateNumbers(10, numbers)
+	events = Stamp(numbers, steppingClock(start, time.Millisecond*250))
+	windows, err = Sliding(events, time.Second, time.Millisecond*500)
+	if err != nil {
+		fmt.Println("Error:", err)
+		return
+	}
+	for w := range windows {
+		fmt.Printf("[%s, %s) values=%v\n",
+			w.Start.Format("05.000"), w.End.Format("05.000"), w.Values())
+	}
+
+	// Bursts of activity separated by quiet periods
+	fmt.Println("\n=== Session Windows ===")
+	ms := time.Millisecond
+	clicks := eventsAt(start,
+		[]time.Duration{0, 100 * ms, 250 * ms, 2000 * ms, 2300 * ms, 5000 * ms},
+		[]string{"home", "search", "product", "cart", "checkout", "home"})
+	sessions, err := Session(clicks, time.Second)
+	if err != nil {
+		fmt.Println("Error:", err)
+		return
+	}
+	for w := range sessions {
+		fmt.Printf("Session %s-%s (%s): %v\n",
+			w.Start.Format("05.000"), w.End.Format("05.000"), w.End.Sub(w.Start), w.Values())
+	}
+
+	// Request latencies, summarised per window with percentiles
+	fmt.Println("\n=== Percentiles ===")
+	rng := rand.New(rand.NewSource(1))
+	latencies := make(chan float64)
+	go func() {
+		defer close(latencies)
+		for i := 0; i < 300; i++ {
+			latency := 20 + rng.ExpFloat64()*30 // Milliseconds, with a long tail
+			if i >= 200 {
+				latency *= 3 // A slow period
+			}
+			latencies <- float64(int(latency))
+		}
+	}()
+	stamped := Stamp(latencies, steppingClock(start, time.Millisecond*10))
+	latencyWindows, err := Tumbling(stamped, time.Second)
+	if err != nil {
+		fmt.Println("Error:", err)
+		return
+	}
+	for stats := range Aggregate(latencyWindows, Summarize[float64]) {
+		fmt.Printf("[%s, %s) count=%d min=%g p50=%g p90=%g p99=%g max=%g\n",
+			stats.Start.Format("05.000"), stats.End.Format("05.000"), stats.Count,
+			stats.Min, stats.Percentile(50), stats.Percentile(90), stats.Percentile(99), stats.Max)
+	}
+
+	// Events that arrive after their window closed are dropped
+	fmt.Println("\n=== Late Events ===")
+	late := eventsAt(start,
+		[]time.Duration{100 * ms, 600 * ms, 1200 * ms, 300 * ms, 1500 * ms},
+		[]int{1, 2, 3, 99, 4})
+	windows, err = Tumbling(late, time.Second)
+	if err != nil {
+		fmt.Println("Error:", err)
+		return
+	}
+	for stats := range Aggregate(windows, Summarize[int]) {
+		fmt.Println(stats)
+	}
+
+	// A window needs a length; a zero size or gap is rejected up front
+	fmt.Println("\n=== Invalid Sizes ===")
+	_, err = Sliding(eventsAt[int](start, nil, nil), time.Second, 0)
+	fmt.Println("Error:", err)
+	_, err = Session(eventsAt[string](start, nil, nil), 0)
+	fmt.Println("Error:", err)
+
+	fmt.Println("\nStream window examples completed")
+}
@@ -0,0 +1,66 @@
+package main
+
+import (
+	"fmt"
+	"math"
+	"sort"
+	"time"
+)
+
+// Number is any value that can be summed and compared
+type Number interface {
+	~int | ~int8 | ~int16 | ~int32 | ~int64 |
+		~uint | ~uint8 | ~uint16 | ~uint32 | ~uint64 |
+		~float32 | ~float64
+}
+
+// Stats are the aggregates of one window
+type Stats struct {
+	Start, End time.Time
+	Count      int
+	Sum        float64
+	Min, Max   float64
+	sorted     []float64
+}
+
+// Summarize computes the aggregates of a window of numbers
+func Summarize[T Number](w Window[T]) Stats {
+	s := Stats{Start: w.Start, End: w.End, Count: len(w.Events)}
+	s.sorted = make([]float64, len(w.Events))
+	for i, e := range w.Events {
+		s.sorted[i] = float64(e.Value)
+		s.Sum += s.sorted[i]
+	}
+	sort.Float64s(s.sorted)
+	if s.Count > 0 {
+		s.Min = s.sorted[0]
+		s.Max = s.sorted[s.Count-1]
+	}
+	return s
+}
+
+// Mean returns the average, or NaN for an empty window
+func (s Stats) Mean() float64 {
+	if s.Count == 0 {
+		return math.NaN()
+	}
+	return s.Sum / float64(s.Count)
+}
+
+// Percentile returns the value below which p percent of the values fall,
+// using the nearest-rank method. It returns NaN for an empty window.
+func (s Stats) Percentile(p float64) float64 {
+	if s.Count == 0 {
+		return math.NaN()
+	}
+	rank := int(math.Ceil(p / 100 * float64(s.Count)))
+	rank = max(1, min(rank, s.Count))
+	return s.sorted[rank-1]
+}
+
+// String formats the window bounds and aggregates on one line
+func (s Stats) String() string {
+	return fmt.Sprintf("[%s, %s) count=%d sum=%g min=%g max=%g mean=%.2f",
+		s.Start.Format("15:04:05.000"), s.End.Format("15:04:05.000"),
+		s.Count, s.Sum, s.Min, s.Max, s.Mean())
+}
@@ -0,0 +1,157 @@
+package main
+
+import (
+	"fmt"
+	"sort"
+	"time"
+)
+
+// Event is a value with the time it happened
+type Event[T any] struct {
+	Time  time.Time
+	Value T
+}
+
+// Window is a closed window and the events that fell in it
+type Window[T any] struct {
+	Start  time.Time // Inclusive
+	End    time.Time // Exclusive
+	Events []Event[T]
+}
+
+// Values returns the event values in arrival order
+func (w Window[T]) Values() []T {
+	values := make([]T, len(w.Events))
+	for i, e := range w.Events {
+		values[i] = e.Value
+	}
+	return values
+}
+
+// Stamp tags each value with the time now returns when it is received
+func Stamp[T any](in <-chan T, now func() time.Time) <-chan Event[T] {
+	out := make(chan Event[T])
+	go func() {
+		defer close(out)
+		for v := range in {
+			out <- Event[T]{Time: now(), Value: v}
+		}
+	}()
+	return out
+}
+
+// Tumbling groups events into back-to-back windows of the given size,
+// aligned to multiples of size. It is a sliding window whose slide is
+// its size.
+func Tumbling[T any](in <-chan Event[T], size time.Duration) (<-chan Window[T], error) {
+	return Sliding(in, size, size)
+}
+
+// Sliding groups events into windows of the given size that start every
+// slide, so an event can be in several windows. Windows close when an
+// event at or after their end arrives, or when in is closed; empty windows
+// are not emitted. Time only moves forward: an event too old for any open
+// window is dropped. size and slide must be positive; otherwise it returns
+// an error and in is not read.
+func Sliding[T any](in <-chan Event[T], size, slide time.Duration) (<-chan Window[T], error) {
+	if size <= 0 || slide <= 0 {
+		return nil, fmt.Errorf("window size %v and slide %v must be positive", size, slide)
+	}
+	out := make(chan Window[T])
+	go func() {
+		defer close(out)
+
+		var open []*Window[T] // Sorted by start
+		var watermark time.Time
+
+		// emit sends every window that ends at or before t
+		emit := func(t time.Time) {
+			n := 0
+			for n < len(open) && !open[n].End.After(t) {
+				out <- *open[n]
+				n++
+			}
+			open = open[n:]
+		}
+
+		for e := range in {
+			if e.Time.After(watermark) {
+				watermark = e.Time
+				emit(watermark)
+			}
+
+			// Every window start in (t-size, t], aligned to slide
+			first := e.Time.Truncate(slide)
+			for start := first; start.After(e.Time.Add(-size)); start = start.Add(-slide) {
+				end := start.Add(size)
+				if !end.After(watermark) {
+					break // This window and all earlier ones have closed
+				}
+				w := findOrInsert(&open, start, end)
+				w.Events = append(w.Events, e)
+			}
+		}
+		emit(watermark.Add(size))
+	}()
+	return out, nil
+}
+
+// findOrInsert returns the open window starting at start, creating it in
+// order if needed
+func findOrInsert[T any](open *[]*Window[T], start, end time.Time) *Window[T] {
+	windows := *open
+	i := sort.Search(len(windows), func(i int) bool { return !windows[i].Start.Before(start) })
+	if i < len(windows) && windows[i].Start.Equal(start) {
+		return windows[i]
+	}
+	w := &Window[T]{Start: start, End: end}
+	windows = append(windows, nil)
+	copy(windows[i+1:], windows[i:])
+	windows[i] = w
+	*open = windows
+	return w
+}
+
+// Session groups events into windows separated by at least gap without
+// events. A window ends gap after its last event. gap must be positive;
+// otherwise it returns an error and in is not read.
+func Session[T any](in <-chan Event[T], gap time.Duration) (<-chan Window[T], error) {
+	if gap <= 0 {
+		return nil, fmt.Errorf("session gap %v must be positive", gap)
+	}
+	out := make(chan Window[T])
+	go func() {
+		defer close(out)
+
+		var current *Window[T]
+		for e := range in {
+			if current != nil && !e.Time.Before(current.End) {
+				out <- *current
+				current = nil
+			}
+			if current == nil {
+				current = &Window[T]{Start: e.Time}
+			}
+			current.Events = append(current.Events, e)
+			if end := e.Time.Add(gap); end.After(current.End) {
+				current.End = end
+			}
+		}
+		if current != nil {
+			out <- *current
+		}
+	}()
+	return out, nil
+}
+
+// Aggregate applies fn to every window, for example Summarize
+func Aggregate[T, R any](in <-chan Window[T], fn func(Window[T]) R) <-chan R {
+	out := make(chan R)
+	go func() {
+		defer close(out)
+		for w := range in {
+			out <- fn(w)
+		}
+	}()
+	return out
+}
@@ -0,0 +1,284 @@
+package main
+
+import (
+	"bytes"
+	"fmt"
+	"os"
+	"runtime"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+// Mutex is a sync.Mutex that records the order in which each goroutine
+// takes locks. Its zero value is ready to use and reports to
+// DefaultDetector, so it can replace a sync.Mutex without other changes.
+type Mutex struct {
+	mu       sync.Mutex
+	Name     string    // Shown in reports; defaults to the mutex's address
+	Detector *Detector // Defaults to DefaultDetector
+}
+
+// Lock records the acquisition, checks it against the lock graph, then
+// locks. The check runs before blocking, so a cycle is reported even if
+// this call is about to deadlock.
+func (m *Mutex) Lock() {
+	d := m.detector()
+	gid := goroutineID()
+	stack := callers()
+	d.beforeLock(m, gid, stack)
+	m.mu.Lock()
+	d.acquired(m, gid, stack)
+}
+
+// TryLock locks if the mutex is free. It cannot block, so it adds nothing
+// to the lock graph, but the lock counts as held for later acquisitions.
+func (m *Mutex) TryLock() bool {
+	if !m.mu.TryLock() {
+		return false
+	}
+	m.detector().acquired(m, goroutineID(), callers())
+	return true
+}
+
+// Unlock releases the lock
+func (m *Mutex) Unlock() {
+	m.detector().released(m)
+	m.mu.Unlock()
+}
+
+func (m *Mutex) detector() *Detector {
+	if m.Detector != nil {
+		return m.Detector
+	}
+	return DefaultDetector
+}
+
+func (m *Mutex) String() string {
+	if m.Name != "" {
+		return m.Name
+	}
+	return fmt.Sprintf("mutex@%p", m)
+}
+
+// heldLock is a lock a goroutine holds and where it took it
+type heldLock struct {
+	mutex *Mutex
+	stack []uintptr
+}
+
+// Edge records that a goroutine took To while holding From, with the
+// stacks of both acquisitions the first time it was seen
+type Edge struct {
+	From, To  *Mutex
+	Goroutine int
+	FromStack []uintptr
+	ToStack   []uintptr
+}
+
+// Cycle is a set of lock-order edges that can deadlock if the goroutines
+// taking them run at the same time
+type Cycle struct {
+	Edges []Edge
+}
+
+// String formats the cycle with both acquisition stacks of every edge
+func (c Cycle) String() string {
+	var b strings.Builder
+	names := make([]string, 0, len(c.Edges)+1)
+	for _, e := range c.Edges {
+		names = append(names, e.From.String())
+	}
+	names = append(names, c.Edges[0].From.String())
+	fmt.Fprintf(&b, "POTENTIAL DEADLOCK: lock order cycle %s\n", strings.Join(names, " -> "))
+
+	for _, e := range c.Edges {
+		fmt.Fprintf(&b, "\ngoroutine %d took %s while holding %s\n", e.Goroutine, e.To, e.From)
+		fmt.Fprintf(&b, "  %s was taken at:\n%s", e.From, formatStack(e.FromStack))
+		fmt.Fprintf(&b, "  %s was taken at:\n%s", e.To, formatStack(e.ToStack))
+	}
+	return b.String()
+}
+
+// Detector builds the lock graph from every Mutex that reports to it and
+// reports each cycle once
+type Detector struct {
+	mu       sync.Mutex
+	held     map[int][]heldLock          // By goroutine ID, in acquisition order
+	edges    map[*Mutex]map[*Mutex]*Edge // From -> To
+	reported map[string]bool
+	onCycle  func(Cycle)
+}
+
+// DefaultDetector is used by mutexes without their own Detector. It
+// prints cycles to standard error.
+var DefaultDetector = NewDetector(func(c Cycle) {
+	fmt.Fprintln(os.Stderr, c)
+})
+
+// Constructor for a detector; onCycle is called once for each new cycle,
+// without any lock held
+func NewDetector(onCycle func(Cycle)) *Detector {
+	return &Detector{
+		held:     make(map[int][]heldLock),
+		edges:    make(map[*Mutex]map[*Mutex]*Edge),
+		reported: make(map[string]bool),
+		onCycle:  onCycle,
+	}
+}
+
+// beforeLock adds an edge from every lock the goroutine holds to m, and
+// reports any cycle the new edges close
+func (d *Detector) beforeLock(m *Mutex, gid int, stack []uintptr) {
+	d.mu.Lock()
+	var cycles []Cycle
+	for _, h := range d.held[gid] {
+		if h.mutex == m {
+			// Locking a mutex the goroutine already holds never returns
+			cycles = append(cycles, Cycle{Edges: []Edge{{From: m, To: m, Goroutine: gid, FromStack: h.stack, ToStack: stack}}})
+			continue
+		}
+		if d.edges[h.mutex] == nil {
+			d.edges[h.mutex] = make(map[*Mutex]*Edge)
+		}
+		if d.edges[h.mutex][m] != nil {
+			continue // Already known, and already checked
+		}
+		edge := &Edge{From: h.mutex, To: m, Goroutine: gid, FromStack: h.stack, ToStack: stack}
+		d.edges[h.mutex][m] = edge
+
+		// The new edge h -> m closes a cycle if m already leads back to h
+		if path := d.pathLocked(m, h.mutex, make(map[*Mutex]bool)); path != nil {
+			cycles = append(cycles, Cycle{Edges: append([]Edge{*edge}, path...)})
+		}
+	}
+
+	var report []Cycle
+	for _, c := range cycles {
+		key := c.key()
+		if !d.reported[key] {
+			d.reported[key] = true
+			report = append(report, c)
+		}
+	}
+	d.mu.Unlock()
+
+	if d.onCycle != nil {
+		for _, c := range report {
+			d.onCycle(c)
+		}
+	}
+}
+
+// pathLocked finds edges leading from one lock to another with a
+// depth-first search; d.mu must be held
+func (d *Detector) pathLocked(from, to *Mutex, visited map[*Mutex]bool) []Edge {
+	if visited[from] {
+		return nil
+	}
+	visited[from] = true
+
+	// Visit in name order so reports do not depend on map order
+	next := make([]*Mutex, 0, len(d.edges[from]))
+	for m := range d.edges[from] {
+		next = append(next, m)
+	}
+	sort.Slice(next, func(i, j int) bool { return next[i].String() < next[j].String() })
+
+	for _, m := range next {
+		edge := *d.edges[from][m]
+		if m == to {
+			return []Edge{edge}
+		}
+		if rest := d.pathLocked(m, to, visited); rest != nil {
+			return append([]Edge{edge}, rest...)
+		}
+	}
+	return nil
+}
+
+// key identifies a cycle by its set of locks, so the same cycle found from
+// a different starting edge is reported once
+func (c Cycle) key() string {
+	names := make([]string, len(c.Edges))
+	for i, e := range c.Edges {
+		names[i] = fmt.Sprintf("%p", e.From)
+	}
+	sort.Strings(names)
+	return strings.Join(names, ",")
+}
+
+// acquired records that the goroutine now holds m
+func (d *Detector) acquired(m *Mutex, gid int, stack []uintptr) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	d.held[gid] = append(d.held[gid], heldLock{mutex: m, stack: stack})
+}
+
+// released forgets m. Go lets another goroutine unlock a mutex, so the
+// holder is looked up rather than assumed to be the caller.
+func (d *Detector) released(m *Mutex) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	for gid, locks := range d.held {
+		for i := len(locks) - 1; i >= 0; i-- {
+			if locks[i].mutex != m {
+				continue
+			}
+			locks = append(locks[:i], locks[i+1:]...)
+			if len(locks) == 0 {
+				delete(d.held, gid)
+			} else {
+				d.held[gid] = locks
+			}
+			return
+		}
+	}
+}
+
+// Edges returns the number of distinct lock-order edges seen so far
+func (d *Detector) Edges() int {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	n := 0
+	for _, to := range d.edges {
+		n += len(to)
+	}
+	return n
+}
+
+// callers captures the stack of the code calling Lock or TryLock
+func callers() []uintptr {
+	pcs := make([]uintptr, 32)
+	n := runtime.Callers(3, pcs) // Skip Callers, callers and the Mutex method
+	return pcs[:n]
+}
+
+// formatStack turns program counters into indented "function file:line" lines
+func formatStack(pcs []uintptr) string {
+	var b strings.Builder
+	frames := runtime.CallersFrames(pcs)
+	for {
+		frame, more := frames.Next()
+		if !strings.HasPrefix(frame.Function, "runtime.") {
+			fmt.Fprintf(&b, "    %s\n        %s:%d\n", frame.Function, frame.File, frame.Line)
+		}
+		if !more {
+			break
+		}
+	}
+	return b.String()
+}
+
+// goroutineID returns the number from the "goroutine 18 [running]:" line
+// that starts the current stack. Go deliberately has no goroutine IDs, and
+// parsing runtime.Stack is slow and depends on an undocumented format;
+// that is only acceptable in a debugging tool like this detector, which
+// needs the ID to know which locks each goroutine holds.
+func goroutineID() int {
+	var buf [32]byte // Long enough for any ID
+	n := runtime.Stack(buf[:], false)
+	id, _ := strconv.Atoi(string(bytes.Fields(buf[:n])[1]))
+	return id
+}
@@ -0,0 +1,174 @@
+package main
+
+import (
+	"strings"
+	"sync"
+	"testing"
+)
+
+// Function that returns a detector collecting its reports, and a way to
+// read them
+func newTestDetector() (*Detector, func() []Cycle) {
+	var mu sync.Mutex
+	var cycles []Cycle
+	d := NewDetector(func(c Cycle) {
+		mu.Lock()
+		defer mu.Unlock()
+		cycles = append(cycles, c)
+	})
+	return d, func() []Cycle {
+		mu.Lock()
+		defer mu.Unlock()
+		return append([]Cycle(nil), cycles...)
+	}
+}
+
+// Function that takes locks in order, then releases them, on a goroutine
+// of its own so the detector sees a separate goroutine each time
+func lockInOrder(locks ...*Mutex) {
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		for _, m := range locks {
+			m.Lock()
+		}
+		for i := len(locks) - 1; i >= 0; i-- {
+			locks[i].Unlock()
+		}
+	}()
+	<-done
+}
+
+func TestTwoLockCycle(t *testing.T) {
+	d, cycles := newTestDetector()
+	a := &Mutex{Name: "A", Detector: d}
+	b := &Mutex{Name: "B", Detector: d}
+
+	// The orders never overlap in time, so nothing actually deadlocks,
+	// but they could
+	lockInOrder(a, b)
+	if got := cycles(); len(got) != 0 {
+		t.Fatalf("reported a cycle after one order:\n%s", got[0])
+	}
+	lockInOrder(b, a)
+
+	got := cycles()
+	if len(got) != 1 {
+		t.Fatalf("got %d cycles, want 1", len(got))
+	}
+	c := got[0]
+	if len(c.Edges) != 2 {
+		t.Fatalf("cycle has %d edges, want 2", len(c.Edges))
+	}
+	// The edge that closed the cycle comes first
+	if c.Edges[0].From != b || c.Edges[0].To != a || c.Edges[1].From != a || c.Edges[1].To != b {
+		t.Errorf("edges %s->%s, %s->%s, want B->A, A->B",
+			c.Edges[0].From, c.Edges[0].To, c.Edges[1].From, c.Edges[1].To)
+	}
+	if c.Edges[0].Goroutine == c.Edges[1].Goroutine {
+		t.Error("both edges attributed to the same goroutine")
+	}
+
+	report := c.String()
+	if !strings.HasPrefix(report, "POTENTIAL DEADLOCK: lock order cycle B -> A -> B\n") {
+		t.Errorf("report header:\n%s", report)
+	}
+	// Every acquisition stack points back at the code that took the lock
+	if n := strings.Count(report, "lockInOrder"); n != 4 {
+		t.Errorf("report has %d stack frames in lockInOrder, want 4:\n%s", n, report)
+	}
+
+	// The same cycle is reported once
+	lockInOrder(b, a)
+	lockInOrder(a, b)
+	if n := len(cycles()); n != 1 {
+		t.Errorf("got %d reports after repeating the orders, want 1", n)
+	}
+}
+
+func TestConsistentOrderNoCycle(t *testing.T) {
+	d, cycles := newTestDetector()
+	a := &Mutex{Name: "A", Detector: d}
+	b := &Mutex{Name: "B", Detector: d}
+	c := &Mutex{Name: "C", Detector: d}
+
+	lockInOrder(a, b)
+	lockInOrder(b, c)
+	lockInOrder(a, c)
+	lockInOrder(a, b, c)
+
+	if got := cycles(); len(got) != 0 {
+		t.Errorf("reported a cycle for a consistent order:\n%s", got[0])
+	}
+	if n := d.Edges(); n != 3 {
+		t.Errorf("got %d edges, want 3", n)
+	}
+}
+
+func TestThreeLockCycle(t *testing.T) {
+	d, cycles := newTestDetector()
+	a := &Mutex{Name: "A", Detector: d}
+	b := &Mutex{Name: "B", Detector: d}
+	c := &Mutex{Name: "C", Detector: d}
+
+	// No two goroutines disagree directly, but together they can deadlock
+	lockInOrder(a, b)
+	lockInOrder(b, c)
+	lockInOrder(c, a)
+
+	got := cycles()
+	if len(got) != 1 {
+		t.Fatalf("got %d cycles, want 1", len(got))
+	}
+	if !strings.HasPrefix(got[0].String(), "POTENTIAL DEADLOCK: lock order cycle C -> A -> B -> C\n") {
+		t.Errorf("report:\n%s", got[0])
+	}
+}
+
+func TestTryLockAddsNoEdge(t *testing.T) {
+	d, cycles := newTestDetector()
+	a := &Mutex{Name: "A", Detector: d}
+	b := &Mutex{Name: "B", Detector: d}
+
+	lockInOrder(a, b)
+
+	// Taking A with TryLock while holding B cannot block, so it is not
+	// part of a deadlock
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		b.Lock()
+		if a.TryLock() {
+			a.Unlock()
+		}
+		b.Unlock()
+	}()
+	<-done
+
+	if got := cycles(); len(got) != 0 {
+		t.Errorf("TryLock reported a cycle:\n%s", got[0])
+	}
+}
+
+func TestUnlockFromAnotherGoroutine(t *testing.T) {
+	d, cycles := newTestDetector()
+	a := &Mutex{Name: "A", Detector: d}
+	b := &Mutex{Name: "B", Detector: d}
+
+	// A is locked here and unlocked elsewhere, so it is no longer held
+	// when this goroutine takes B
+	a.Lock()
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		a.Unlock()
+	}()
+	<-done
+	b.Lock()
+	b.Unlock()
+
+	lockInOrder(b, a)
+	if got := cycles(); len(got) != 0 {
+		t.Errorf("released lock still counted as held:\n%s", got[0])
+	}
+}
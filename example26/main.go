@@ -0,0 +1,149 @@
+package main
+
+import (
+	"fmt"
+	"sync"
+)
+
+// Example 26: Lock Order Checking
+// Demonstrates a mutex that records lock acquisition order and reports
+// potential deadlocks, even in runs where no deadlock happened
+
+// Function that demonstrates a mutex, as in example 9. The only change is
+// the type of mutex: Mutex instead of sync.Mutex.
+func mutexExample() {
+	var counter = 0
+	var mutex Mutex
+	var wg sync.WaitGroup
+
+	// Launch 10 goroutines that increment the counter
+	for i := 0; i < 10; i++ {
+		wg.Add(1)
+		go func(id int) {
+			defer wg.Done()
+
+			for j := 0; j < 1000; j++ {
+				// Lock the mutex to safely update the counter
+				mutex.Lock()
+				counter++
+				mutex.Unlock()
+			}
+		}(i)
+	}
+
+	wg.Wait()
+	fmt.Printf("Final counter value: %d\n", counter)
+}
+
+// Account is a bank account with its own lock
+type Account struct {
+	ID      int
+	Balance int
+	mu      Mutex
+}
+
+// Constructor for an account whose lock reports to detector
+func NewAccount(id, balance int, detector *Detector) *Account {
+	a := &Account{ID: id, Balance: balance}
+	a.mu = Mutex{Name: fmt.Sprintf("account-%d", id), Detector: detector}
+	return a
+}
+
+// Function that moves money, locking the source then the destination.
+// Two opposite transfers running at once can deadlock.
+func transfer(from, to *Account, amount int) {
+	from.mu.Lock()
+	defer from.mu.Unlock()
+	to.mu.Lock()
+	defer to.mu.Unlock()
+
+	from.Balance -= amount
+	to.Balance += amount
+}
+
+// Function that moves money, always locking the lower account ID first
+func orderedTransfer(from, to *Account, amount int) {
+	first, second := from, to
+	if second.ID < first.ID {
+		first, second = second, first
+	}
+	first.mu.Lock()
+	defer first.mu.Unlock()
+	second.mu.Lock()
+	defer second.mu.Unlock()
+
+	from.Balance -= amount
+	to.Balance += amount
+}
+
+// Function that runs fn in a goroutine and waits for it, so the calls in
+// a demo never overlap and never actually deadlock
+func runAlone(fn func()) {
+	var wg sync.WaitGroup
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		fn()
+	}()
+	wg.Wait()
+}
+
+func main() {
+	fmt.Println("Lock Order Checking in Go:")
+
+	// Drop-in use: one lock, so no order to get wrong
+	fmt.Println("\n=== Drop-in Mutex ===")
+	mutexExample()
+	fmt.Println("Lock order edges:", DefaultDetector.Edges())
+
+	// Opposite transfers, one after the other. This run finishes, but the
+	// detector sees that the two lock orders could deadlock.
+	fmt.Println("\n=== Potential Deadlock ===")
+	var cycles []Cycle
+	detector := NewDetector(func(c Cycle) { cycles = append(cycles, c) })
+	alice := NewAccount(1, 100, detector)
+	bob := NewAccount(2, 100, detector)
+
+	runAlone(func() { transfer(alice, bob, 10) })
+	runAlone(func() { transfer(bob, alice, 5) })
+	fmt.Printf("Balances: alice=%d bob=%d\n", alice.Balance, bob.Balance)
+	fmt.Println("Cycles found:", len(cycles))
+	for _, c := range cycles {
+		fmt.Println(c)
+	}
+
+	// The same cycle through three locks, as with dining philosophers
+	fmt.Println("=== Three-Lock Cycle ===")
+	cycles = nil
+	detector = NewDetector(func(c Cycle) { cycles = append(cycles, c) })
+	accounts := []*Account{NewAccount(1, 100, detector), NewAccount(2, 100, detector), NewAccount(3, 100, detector)}
+	for i := range accounts {
+		from, to := accounts[i], accounts[(i+1)%len(accounts)]
+		runAlone(func() { transfer(from, to, 1) })
+	}
+	for _, c := range cycles {
+		fmt.Printf("Cycle through %d locks:", len(c.Edges))
+		for _, e := range c.Edges {
+			fmt.Printf(" %s->%s", e.From, e.To)
+		}
+		fmt.Println()
+	}
+
+	// A consistent lock order has no cycles, however transfers interleave
+	fmt.Println("\n=== Consistent Order ===")
+	cycles = nil
+	detector = NewDetector(func(c Cycle) { cycles = append(cycles, c) })
+	alice = NewAccount(1, 100, detector)
+	bob = NewAccount(2, 100, detector)
+	var wg sync.WaitGroup
+	for i := 0; i < 100; i++ {
+		wg.Add(2)
+		go func() { defer wg.Done(); orderedTransfer(alice, bob, 1) }()
+		go func() { defer wg.Done(); orderedTransfer(bob, alice, 1) }()
+	}
+	wg.Wait()
+	fmt.Printf("Balances: alice=%d bob=%d\n", alice.Balance, bob.Balance)
+	fmt.Println("Lock order edges:", detector.Edges(), "cycles found:", len(cycles))
+
+	fmt.Println("\nLock order checking examples completed")
+}
@@ -0,0 +1,210 @@
+package main
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"sort"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// FileHash is the result of hashing one file
+type FileHash struct {
+	Path string // Relative to the root, with forward slashes
+	Size int64
+	Sum  string // Hex SHA-256; empty if Err is set
+	Err  error
+}
+
+// walkFiles lists the regular files under root, relative to it and
+// sorted. Symlinks are skipped, and so are paths in exclude.
+func walkFiles(root string, exclude map[string]bool) ([]string, int64, error) {
+	var paths []string
+	var total int64
+	err := filepath.WalkDir(root, func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if !d.Type().IsRegular() {
+			return nil
+		}
+		rel, err := filepath.Rel(root, path)
+		if err != nil {
+			return err
+		}
+		rel = filepath.ToSlash(rel)
+		if exclude[rel] {
+			return nil
+		}
+		info, err := d.Info()
+		if err != nil {
+			return err
+		}
+		paths = append(paths, rel)
+		total += info.Size()
+		return nil
+	})
+	if err != nil {
+		return nil, 0, fmt.Errorf("failed to walk %s: %w", root, err)
+	}
+	sort.Strings(paths)
+	return paths, total, nil
+}
+
+// hashFile computes the SHA-256 of one file, streaming it rather than
+// reading it into memory
+func hashFile(path string, progress *Progress) (string, int64, error) {
+	// Open the file
+	file, err := os.Open(path)
+	if err != nil {
+		return "", 0, fmt.Errorf("failed to open file: %w", err)
+	}
+	defer file.Close()
+
+	h := sha256.New()
+	n, err := io.Copy(h, progress.counting(file))
+	if err != nil {
+		return "", n, fmt.Errorf("failed to read file: %w", err)
+	}
+	return hex.EncodeToString(h.Sum(nil)), n, nil
+}
+
+// Worker that hashes files from the jobs channel, as in example 9
+func worker(root string, jobs <-chan string, results chan<- FileHash, progress *Progress, wg *sync.WaitGroup) {
+	defer wg.Done()
+	for rel := range jobs {
+		sum, size, err := hashFile(filepath.Join(root, filepath.FromSlash(rel)), progress)
+		results <- FileHash{Path: rel, Size: size, Sum: sum, Err: err}
+		progress.files.Add(1)
+	}
+}
+
+// hashFiles hashes paths under root with the given number of workers and
+// returns the results sorted by path
+func hashFiles(root string, paths []string, workers int, progress *Progress) []FileHash {
+	jobs := make(chan string)
+	results := make(chan FileHash, workers)
+
+	// Start workers
+	var wg sync.WaitGroup
+	for w := 0; w < workers; w++ {
+		wg.Add(1)
+		go worker(root, jobs, results, progress, &wg)
+	}
+
+	// Send jobs
+	go func() {
+		for _, p := range paths {
+			jobs <- p
+		}
+		close(jobs)
+	}()
+
+	// Close the results channel when all workers are done
+	go func() {
+		wg.Wait()
+		close(results)
+	}()
+
+	hashes := make([]FileHash, 0, len(paths))
+	for result := range results {
+		hashes = append(hashes, result)
+	}
+	sort.Slice(hashes, func(i, j int) bool { return hashes[i].Path < hashes[j].Path })
+	return hashes
+}
+
+// findDuplicates groups the paths of files with the same content. Groups
+// are sorted by their first path; empty files are not reported.
+func findDuplicates(hashes []FileHash) [][]string {
+	bySum := make(map[string][]string)
+	for _, h := range hashes {
+		if h.Err == nil && h.Size > 0 {
+			bySum[h.Sum] = append(bySum[h.Sum], h.Path)
+		}
+	}
+
+	var groups [][]string
+	for _, paths := range bySum {
+		if len(paths) > 1 {
+			groups = append(groups, paths) // Already in path order
+		}
+	}
+	sort.Slice(groups, func(i, j int) bool { return groups[i][0] < groups[j][0] })
+	return groups
+}
+
+// Progress counts hashed files and bytes so they can be reported while
+// the workers run
+type Progress struct {
+	totalFiles int
+	totalBytes int64
+	files      atomic.Int64
+	bytes      atomic.Int64
+}
+
+// Constructor for progress over a known amount of work
+func NewProgress(totalFiles int, totalBytes int64) *Progress {
+	return &Progress{totalFiles: totalFiles, totalBytes: totalBytes}
+}
+
+// counting wraps r so bytes read are added to the progress
+func (p *Progress) counting(r io.Reader) io.Reader {
+	return countingReader{r: r, n: &p.bytes}
+}
+
+type countingReader struct {
+	r io.Reader
+	n *atomic.Int64
+}
+
+func (c countingReader) Read(b []byte) (int, error) {
+	n, err := c.r.Read(b)
+	c.n.Add(int64(n))
+	return n, err
+}
+
+// String formats the progress as files and megabytes done. The byte
+// total is left out if it is not known.
+func (p *Progress) String() string {
+	s := fmt.Sprintf("%d/%d files, %.1f", p.files.Load(), p.totalFiles, float64(p.bytes.Load())/1e6)
+	if p.totalBytes > 0 {
+		s += fmt.Sprintf("/%.1f", float64(p.totalBytes)/1e6)
+	}
+	return s + " MB"
+}
+
+// Report prints the progress to w every interval until the returned stop
+// function is called, which prints it one last time. An interval of zero
+// prints nothing.
+func (p *Progress) Report(w io.Writer, interval time.Duration) (stop func()) {
+	if interval <= 0 {
+		return func() {}
+	}
+	done := make(chan struct{})
+	finished := make(chan struct{})
+	go func() {
+		defer close(finished)
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-ticker.C:
+				fmt.Fprintln(w, "progress:", p)
+			case <-done:
+				fmt.Fprintln(w, "progress:", p)
+				return
+			}
+		}
+	}()
+	return func() {
+		close(done)
+		<-finished
+	}
+}
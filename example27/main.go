@@ -0,0 +1,252 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"time"
+)
+
+// Example 27: Parallel File Hashing
+// Demonstrates walking a directory tree and hashing files with a worker
+// pool, reporting duplicates and writing or verifying a checksum manifest
+
+// Options for one hashing run
+type Options struct {
+	Root     string
+	Workers  int
+	Write    string // Manifest to write, if set
+	Verify   string // Manifest to verify, if set
+	Progress time.Duration
+}
+
+// Function that hashes the tree and reports on it. It returns false if
+// verification failed or, when hashing, if any file could not be read;
+// those files are left out of a written manifest.
+func run(opts Options, out, progressOut io.Writer) (bool, error) {
+	// A manifest inside the tree is not part of what it describes. Rel
+	// needs both paths in the same form, so make them absolute first.
+	root, err := filepath.Abs(opts.Root)
+	if err != nil {
+		return false, fmt.Errorf("failed to resolve %s: %w", opts.Root, err)
+	}
+	exclude := make(map[string]bool)
+	for _, manifest := range []string{opts.Write, opts.Verify} {
+		if manifest == "" {
+			continue
+		}
+		abs, err := filepath.Abs(manifest)
+		if err != nil {
+			return false, fmt.Errorf("failed to resolve %s: %w", manifest, err)
+		}
+		if rel, err := filepath.Rel(root, abs); err == nil && insideRoot(rel) {
+			exclude[filepath.ToSlash(rel)] = true
+		}
+	}
+
+	paths, totalBytes, err := walkFiles(root, exclude)
+	if err != nil {
+		return false, err
+	}
+
+	if opts.Verify != "" {
+		entries, err := ReadManifest(opts.Verify)
+		if err != nil {
+			return false, err
+		}
+		progress := NewProgress(len(entries), 0)
+		stop := progress.Report(progressOut, opts.Progress)
+		result := Verify(root, entries, paths, opts.Workers, progress)
+		stop()
+
+		for _, p := range result.Failed {
+			fmt.Fprintf(out, "FAILED   %s\n", p)
+		}
+		for _, p := range result.Missing {
+			fmt.Fprintf(out, "MISSING  %s\n", p)
+		}
+		for _, h := range result.Unreadable {
+			fmt.Fprintf(out, "ERROR    %s: %v\n", h.Path, h.Err)
+		}
+		for _, p := range result.Outside {
+			fmt.Fprintf(out, "OUTSIDE  %s\n", p)
+		}
+		for _, p := range result.Unlisted {
+			fmt.Fprintf(out, "UNLISTED %s\n", p)
+		}
+		fmt.Fprintf(out, "Verified %d files: %d ok, %d failed, %d missing, %d unreadable, %d outside the root, %d unlisted\n",
+			len(entries), len(result.OK), len(result.Failed), len(result.Missing), len(result.Unreadable),
+			len(result.Outside), len(result.Unlisted))
+		return result.Passed(), nil
+	}
+
+	progress := NewProgress(len(paths), totalBytes)
+	stop := progress.Report(progressOut, opts.Progress)
+	start := time.Now()
+	hashes := hashFiles(root, paths, opts.Workers, progress)
+	stop()
+	fmt.Fprintf(out, "Hashed %d files (%.1f MB) in %v with %d workers\n",
+		len(hashes), float64(totalBytes)/1e6, time.Since(start).Round(time.Millisecond), opts.Workers)
+
+	complete := true
+	for _, h := range hashes {
+		if h.Err != nil {
+			fmt.Fprintln(out, "Error:", h.Err)
+			complete = false
+		}
+	}
+	for _, group := range findDuplicates(hashes) {
+		fmt.Fprintf(out, "Duplicates: %s\n", strings.Join(group, ", "))
+	}
+
+	if opts.Write != "" {
+		if err := WriteManifest(opts.Write, hashes); err != nil {
+			return false, err
+		}
+		fmt.Fprintln(out, "Wrote manifest", opts.Write)
+	}
+	return complete, nil
+}
+
+// Function that builds a small tree of build artifacts with duplicates
+func makeArtifacts(root string) error {
+	files := map[string]string{
+		"bin/app":            strings.Repeat("app binary ", 50000),
+		"bin/app-v1.2":       strings.Repeat("app binary ", 50000),
+		"lib/libutil.so":     strings.Repeat("shared library ", 20000),
+		"lib/vendor/util.so": strings.Repeat("shared library ", 20000),
+		"docs/README.txt":    "Build artifacts for release 1.2\n",
+		"docs/CHANGES.txt":   "- Faster startup\n",
+		"config/app.yaml":    "port: 8080\n",
+		"config/empty.yaml":  "",
+		"config/blank.yaml":  "",
+	}
+	for name, content := range files {
+		path := filepath.Join(root, filepath.FromSlash(name))
+		if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+			return fmt.Errorf("failed to create directory: %w", err)
+		}
+		if err := os.WriteFile(path, []byte(content), 0o644); err != nil {
+			return fmt.Errorf("failed to write file: %w", err)
+		}
+	}
+	return nil
+}
+
+// Function that runs the tool against a temporary tree: hash it, write a
+// manifest, change the tree, then verify
+func demo(workers int, progressInterval time.Duration) error {
+	root, err := os.MkdirTemp("", "artifacts")
+	if err != nil {
+		return fmt.Errorf("failed to create temp dir: %w", err)
+	}
+	defer os.RemoveAll(root)
+
+	if err := makeArtifacts(root); err != nil {
+		return err
+	}
+	manifest := filepath.Join(root, "SHA256SUMS")
+	opts := Options{Root: root, Workers: workers, Progress: progressInterval}
+
+	fmt.Println("\n=== Hash and Find Duplicates ===")
+	writeOpts := opts
+	writeOpts.Write = manifest
+	if _, err := run(writeOpts, os.Stdout, os.Stdout); err != nil {
+		return err
+	}
+
+	fmt.Println("\n=== Manifest ===")
+	content, err := os.ReadFile(manifest)
+	if err != nil {
+		return fmt.Errorf("failed to read manifest: %w", err)
+	}
+	for _, line := range strings.Split(strings.TrimSpace(string(content)), "\n") {
+		fmt.Println(line[:12] + "... " + line[66:])
+	}
+
+	verifyOpts := opts
+	verifyOpts.Verify = manifest
+
+	fmt.Println("\n=== Verify Unchanged ===")
+	ok, err := run(verifyOpts, os.Stdout, os.Stdout)
+	if err != nil {
+		return err
+	}
+	fmt.Println("Passed:", ok)
+
+	// Corrupt one artifact, delete another and add a new one
+	fmt.Println("\n=== Verify After Changes ===")
+	if err := os.WriteFile(filepath.Join(root, "config", "app.yaml"), []byte("port: 9090\n"), 0o644); err != nil {
+		return fmt.Errorf("failed to write file: %w", err)
+	}
+	if err := os.Remove(filepath.Join(root, "docs", "CHANGES.txt")); err != nil {
+		return fmt.Errorf("failed to remove file: %w", err)
+	}
+	if err := os.WriteFile(filepath.Join(root, "bin", "debug.log"), []byte("trace\n"), 0o644); err != nil {
+		return fmt.Errorf("failed to write file: %w", err)
+	}
+	ok, err = run(verifyOpts, os.Stdout, os.Stdout)
+	if err != nil {
+		return err
+	}
+	fmt.Println("Passed:", ok)
+
+	// A manifest from elsewhere may point outside the tree, and a listed
+	// file may exist but fail to read
+	fmt.Println("\n=== Verify Untrusted Manifest ===")
+	file, err := os.OpenFile(manifest, os.O_APPEND|os.O_WRONLY, 0)
+	if err != nil {
+		return fmt.Errorf("failed to open manifest: %w", err)
+	}
+	zeros := strings.Repeat("0", 64)
+	fmt.Fprintf(file, "%s  ../../etc/passwd\n%s  /etc/hostname\n%s  docs/api\n", zeros, zeros, zeros)
+	if err := file.Close(); err != nil {
+		return fmt.Errorf("failed to write manifest: %w", err)
+	}
+	if err := os.Mkdir(filepath.Join(root, "docs", "api"), 0o755); err != nil {
+		return fmt.Errorf("failed to create directory: %w", err)
+	}
+	ok, err = run(verifyOpts, os.Stdout, os.Stdout)
+	if err != nil {
+		return err
+	}
+	fmt.Println("Passed:", ok)
+	return nil
+}
+
+func main() {
+	dir := flag.String("dir", "", "directory to hash; runs a demo on a temporary tree if empty")
+	workers := flag.Int("workers", runtime.NumCPU(), "number of files hashed in parallel")
+	write := flag.String("write", "", "write a checksum manifest to this path")
+	verify := flag.String("verify", "", "verify the directory against this manifest")
+	progress := flag.Duration("progress", time.Second, "interval between progress lines on stderr; 0 disables")
+	flag.Parse()
+
+	if *workers < 1 {
+		fmt.Fprintln(os.Stderr, "Error: -workers must be at least 1")
+		os.Exit(2)
+	}
+
+	if *dir == "" {
+		fmt.Println("Parallel File Hashing in Go:")
+		if err := demo(*workers, 0); err != nil {
+			fmt.Println("Error:", err)
+			os.Exit(1)
+		}
+		fmt.Println("\nParallel file hashing examples completed")
+		return
+	}
+
+	ok, err := run(Options{Root: *dir, Workers: *workers, Write: *write, Verify: *verify, Progress: *progress}, os.Stdout, os.Stderr)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, "Error:", err)
+		os.Exit(1)
+	}
+	if !ok {
+		os.Exit(1)
+	}
+}
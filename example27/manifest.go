@@ -0,0 +1,156 @@
+package main
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// The manifest uses the sha256sum format, one "<hex>  <path>" line per
+// file, so it can also be checked with "sha256sum -c"
+
+// ManifestEntry is one line of a manifest
+type ManifestEntry struct {
+	Sum  string
+	Path string
+}
+
+// WriteManifest writes the hashes of every file that could be read. It
+// writes to a temporary file and renames it, so a failed run never leaves
+// a half-written manifest behind.
+func WriteManifest(path string, hashes []FileHash) error {
+	tmp, err := os.CreateTemp(filepath.Dir(path), ".manifest-*")
+	if err != nil {
+		return fmt.Errorf("failed to create manifest: %w", err)
+	}
+	defer os.Remove(tmp.Name()) // Fails harmlessly after the rename
+
+	w := bufio.NewWriter(tmp)
+	for _, h := range hashes {
+		if h.Err != nil {
+			continue
+		}
+		fmt.Fprintf(w, "%s  %s\n", h.Sum, h.Path)
+	}
+	if err := w.Flush(); err != nil {
+		tmp.Close()
+		return fmt.Errorf("failed to write manifest: %w", err)
+	}
+	if err := tmp.Close(); err != nil {
+		return fmt.Errorf("failed to write manifest: %w", err)
+	}
+	if err := os.Rename(tmp.Name(), path); err != nil {
+		return fmt.Errorf("failed to replace manifest: %w", err)
+	}
+	return nil
+}
+
+// ReadManifest parses a manifest written by WriteManifest or sha256sum
+func ReadManifest(path string) ([]ManifestEntry, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open manifest: %w", err)
+	}
+	defer file.Close()
+
+	var entries []ManifestEntry
+	scanner := bufio.NewScanner(file)
+	for line := 1; scanner.Scan(); line++ {
+		text := scanner.Text()
+		if text == "" {
+			continue
+		}
+		// sha256sum marks binary mode with "*" instead of the second space
+		sum, rest, ok := strings.Cut(text, " ")
+		if !ok || len(sum) != 64 || len(rest) < 2 || (rest[0] != ' ' && rest[0] != '*') {
+			return nil, fmt.Errorf("manifest line %d: malformed entry %q", line, text)
+		}
+		entries = append(entries, ManifestEntry{Sum: sum, Path: rest[1:]})
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("failed to read manifest: %w", err)
+	}
+	return entries, nil
+}
+
+// VerifyResult sorts the files into what changed since the manifest was written
+type VerifyResult struct {
+	OK         []string
+	Failed     []string   // Content differs
+	Missing    []string   // Listed but no longer exists
+	Unreadable []FileHash // Listed and present, but could not be read; Err says why
+	Outside    []string   // Listed with a path that leaves the root or goes through a symlink, so not read
+	Unlisted   []string   // Present but not in the manifest
+}
+
+// Passed reports whether every listed file matched
+func (r VerifyResult) Passed() bool {
+	return len(r.Failed) == 0 && len(r.Missing) == 0 && len(r.Unreadable) == 0 && len(r.Outside) == 0
+}
+
+// insideRoot reports whether a path from filepath.Rel stays under the root.
+// Only a leading ".." element leaves it; a name like "..data" does not.
+func insideRoot(rel string) bool {
+	return rel != ".." && !strings.HasPrefix(rel, ".."+string(filepath.Separator))
+}
+
+// throughSymlink reports whether any element of rel, a local path under
+// root, is a symlink. It stops at the first element that cannot be
+// examined and leaves that error for hashing to report.
+func throughSymlink(root, rel string) bool {
+	path := root
+	for _, name := range strings.Split(filepath.FromSlash(rel), string(filepath.Separator)) {
+		path = filepath.Join(path, name)
+		info, err := os.Lstat(path)
+		if err != nil {
+			return false
+		}
+		if info.Mode()&fs.ModeSymlink != 0 {
+			return true
+		}
+	}
+	return false
+}
+
+// Verify hashes the files listed in entries and compares them. present is
+// every file now under root, used to find unlisted ones. Entries with an
+// absolute path, one that climbs out of root with "..", or one that goes
+// through a symlink are not read. The symlink check happens before hashing,
+// so a link swapped in while Verify runs is still followed; do not verify a
+// tree that someone else can write to while it is being checked.
+func Verify(root string, entries []ManifestEntry, present []string, workers int, progress *Progress) VerifyResult {
+	var result VerifyResult
+	expected := make(map[string]string, len(entries))
+	paths := make([]string, 0, len(entries))
+	for _, e := range entries {
+		if !filepath.IsLocal(filepath.FromSlash(e.Path)) || throughSymlink(root, e.Path) {
+			result.Outside = append(result.Outside, e.Path)
+			continue
+		}
+		expected[e.Path] = e.Sum
+		paths = append(paths, e.Path)
+	}
+
+	for _, h := range hashFiles(root, paths, workers, progress) {
+		switch {
+		case errors.Is(h.Err, fs.ErrNotExist):
+			result.Missing = append(result.Missing, h.Path)
+		case h.Err != nil:
+			result.Unreadable = append(result.Unreadable, h)
+		case h.Sum != expected[h.Path]:
+			result.Failed = append(result.Failed, h.Path)
+		default:
+			result.OK = append(result.OK, h.Path)
+		}
+	}
+	for _, p := range present {
+		if _, ok := expected[p]; !ok {
+			result.Unlisted = append(result.Unlisted, p)
+		}
+	}
+	return result
+}
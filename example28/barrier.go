@@ -0,0 +1,109 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"sync"
+)
+
+// ErrBrokenBarrier is returned by Barrier.Await when a party gave up
+// waiting, so the others would otherwise wait forever
+var ErrBrokenBarrier = errors.New("barrier broken")
+
+// generation is one trip of a barrier. done is closed when every party has
+// arrived, or when the barrier breaks.
+type generation struct {
+	done   chan struct{}
+	broken bool
+}
+
+// Barrier blocks a fixed number of parties until all of them have called
+// Await, then releases them together and resets for the next round
+type Barrier struct {
+	mu      sync.Mutex
+	parties int
+	waiting int
+	gen     *generation
+	action  func() // Run by the last party to arrive, before the others wake; must not call the barrier
+}
+
+// Constructor for a barrier; action may be nil
+func NewBarrier(parties int, action func()) *Barrier {
+	if parties < 1 {
+		panic("barrier needs at least one party")
+	}
+	return &Barrier{parties: parties, gen: &generation{done: make(chan struct{})}, action: action}
+}
+
+// Await blocks until every party has arrived. If ctx ends first the barrier
+// breaks: this call returns ctx's error and every other waiter gets
+// ErrBrokenBarrier, until Reset is called.
+func (b *Barrier) Await(ctx context.Context) error {
+	b.mu.Lock()
+	g := b.gen
+	if g.broken {
+		b.mu.Unlock()
+		return ErrBrokenBarrier
+	}
+
+	b.waiting++
+	if b.waiting == b.parties {
+		if b.action != nil {
+			b.action()
+		}
+		b.nextLocked()
+		b.mu.Unlock()
+		return nil
+	}
+	b.mu.Unlock()
+
+	select {
+	case <-g.done:
+		if g.broken {
+			return ErrBrokenBarrier
+		}
+		return nil
+	case <-ctx.Done():
+		b.mu.Lock()
+		defer b.mu.Unlock()
+		select {
+		case <-g.done:
+			// The barrier tripped while we were cancelled; count it
+			if g.broken {
+				return ErrBrokenBarrier
+			}
+			return nil
+		default:
+		}
+		g.broken = true
+		close(g.done)
+		return ctx.Err()
+	}
+}
+
+// nextLocked releases the current round and starts the next; b.mu must be held
+func (b *Barrier) nextLocked() {
+	close(b.gen.done)
+	b.gen = &generation{done: make(chan struct{})}
+	b.waiting = 0
+}
+
+// Reset repairs a broken barrier. Parties waiting in the current round
+// get ErrBrokenBarrier.
+func (b *Barrier) Reset() {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if !b.gen.broken {
+		b.gen.broken = true
+		close(b.gen.done)
+	}
+	b.gen = &generation{done: make(chan struct{})}
+	b.waiting = 0
+}
+
+// Waiting returns how many parties are blocked in the current round
+func (b *Barrier) Waiting() int {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.waiting
+}
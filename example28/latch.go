@@ -0,0 +1,76 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"time"
+)
+
+// ErrLatchTimeout is returned by WaitTimeout when the count did not reach zero in time
+var ErrLatchTimeout = errors.New("latch wait timed out")
+
+// Latch lets goroutines wait until a count of events has happened. Unlike
+// a WaitGroup it is waited on with a context or timeout, and counting down
+// past zero is harmless.
+type Latch struct {
+	mu    sync.Mutex
+	count int
+	done  chan struct{}
+}
+
+// Constructor for a latch that opens after count calls to CountDown
+func NewLatch(count int) *Latch {
+	l := &Latch{count: count, done: make(chan struct{})}
+	if count <= 0 {
+		close(l.done)
+	}
+	return l
+}
+
+// CountDown records one event, opening the latch at zero
+func (l *Latch) CountDown() {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	if l.count == 0 {
+		return
+	}
+	l.count--
+	if l.count == 0 {
+		close(l.done)
+	}
+}
+
+// Count returns how many events are still outstanding
+func (l *Latch) Count() int {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	return l.count
+}
+
+// Done is closed when the latch opens
+func (l *Latch) Done() <-chan struct{} {
+	return l.done
+}
+
+// Wait blocks until the latch opens or ctx ends
+func (l *Latch) Wait(ctx context.Context) error {
+	select {
+	case <-l.done:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// WaitTimeout blocks until the latch opens or d passes
+func (l *Latch) WaitTimeout(d time.Duration) error {
+	timer := time.NewTimer(d)
+	defer timer.Stop()
+	select {
+	case <-l.done:
+		return nil
+	case <-timer.C:
+		return ErrLatchTimeout
+	}
+}
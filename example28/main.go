@@ -0,0 +1,221 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+)
+
+// Example 28: Coordination Primitives
+// Demonstrates cyclic barriers, phasers, countdown latches and weighted
+// semaphores, all with context-aware waits
+
+// Function that runs a simulation where every worker must finish a tick
+// before any worker starts the next
+func barrierExample() {
+	const workers, ticks = 3, 3
+	positions := make([]int, workers)
+	barrier := NewBarrier(workers, func() {
+		// Runs once per tick, after every worker has moved
+		fmt.Println("  tick complete, positions:", positions)
+	})
+
+	var wg sync.WaitGroup
+	for w := 0; w < workers; w++ {
+		wg.Add(1)
+		go func(id int) {
+			defer wg.Done()
+			for tick := 1; tick <= ticks; tick++ {
+				time.Sleep(time.Millisecond * time.Duration(5*(id+1)))
+				positions[id] += id + 1
+				if err := barrier.Await(context.Background()); err != nil {
+					fmt.Println("Error:", err)
+					return
+				}
+			}
+		}(w)
+	}
+	wg.Wait()
+}
+
+// Function that shows a barrier breaking when one worker gives up, so the
+// others are not left waiting forever
+func brokenBarrierExample() {
+	barrier := NewBarrier(3, nil)
+	var wg sync.WaitGroup
+	for w := 0; w < 2; w++ {
+		wg.Add(1)
+		go func(id int) {
+			defer wg.Done()
+			ctx := context.Background()
+			if id == 0 {
+				var cancel context.CancelFunc
+				ctx, cancel = context.WithTimeout(ctx, time.Millisecond*20)
+				defer cancel()
+			}
+			fmt.Printf("  worker %d: %v\n", id, barrier.Await(ctx))
+		}(w)
+	}
+	wg.Wait()
+
+	fmt.Println("  later arrival:", barrier.Await(context.Background()))
+	barrier.Reset()
+	fmt.Println("  after reset, waiting:", barrier.Waiting())
+}
+
+// Function that runs a simulation where workers join and leave between ticks
+func phaserExample() {
+	phaser := NewPhaser(1, func(phase, parties int) {
+		fmt.Printf("  tick %d done, %d parties registered\n", phase, parties)
+	}) // The coordinator is the first party
+
+	var wg sync.WaitGroup
+	startWorker := func(name string, ticks int) {
+		phase, err := phaser.Register()
+		if err != nil {
+			fmt.Println("Error:", err)
+			return
+		}
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			fmt.Printf("  %s joins at tick %d\n", name, phase)
+			for i := 0; i < ticks-1; i++ {
+				time.Sleep(time.Millisecond * 5)
+				if _, err := phaser.ArriveAndAwait(context.Background()); err != nil {
+					fmt.Println("Error:", err)
+					return
+				}
+			}
+			fmt.Printf("  %s leaves at tick %d\n", name, phaser.Phase())
+			phaser.ArriveAndDeregister()
+		}()
+	}
+
+	// The coordinator adds workers at the start of some ticks
+	startWorker("worker-a", 4)
+	startWorker("worker-b", 2)
+	for tick := 0; tick < 4; tick++ {
+		if tick == 2 {
+			startWorker("worker-c", 2)
+		}
+		if _, err := phaser.ArriveAndAwait(context.Background()); err != nil {
+			fmt.Println("Error:", err)
+		}
+	}
+	phaser.ArriveAndDeregister()
+	wg.Wait()
+
+	_, err := phaser.Register()
+	fmt.Println("  register after all left:", err)
+
+	// Leaving a phaser nobody joined is an error, not negative parties
+	_, err = NewPhaser(0, nil).ArriveAndDeregister()
+	fmt.Println("  deregister with no parties:", err)
+}
+
+// Function that waits for services to start, with a timeout
+func latchExample() {
+	services := map[string]time.Duration{
+		"database": time.Millisecond * 10,
+		"cache":    time.Millisecond * 20,
+		"search":   time.Millisecond * 80,
+	}
+	latch := NewLatch(len(services))
+	for name, delay := range services {
+		go func(name string, delay time.Duration) {
+			time.Sleep(delay)
+			fmt.Printf("  %s started\n", name)
+			latch.CountDown()
+		}(name, delay)
+	}
+
+	if err := latch.WaitTimeout(time.Millisecond * 50); err != nil {
+		fmt.Printf("  Error: %v, %d still starting\n", err, latch.Count())
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), time.Millisecond*100)
+	defer cancel()
+	fmt.Println("  all started:", latch.Wait(ctx) == nil)
+}
+
+// Function that limits jobs by the memory they need, not their number
+func semaphoreExample() {
+	const capacityMB = 10
+	sem, err := NewSemaphore(capacityMB)
+	if err != nil {
+		fmt.Println("Error:", err)
+		return
+	}
+	jobs := []int64{4, 6, 2, 8, 3, 5, 1}
+
+	var mu sync.Mutex
+	peak := int64(0)
+	var wg sync.WaitGroup
+	for i, mb := range jobs {
+		wg.Add(1)
+		go func(id int, mb int64) {
+			defer wg.Done()
+			if err := sem.Acquire(context.Background(), mb); err != nil {
+				fmt.Println("Error:", err)
+				return
+			}
+			defer func() {
+				if err := sem.Release(mb); err != nil {
+					fmt.Println("Error:", err)
+				}
+			}()
+
+			mu.Lock()
+			peak = max(peak, sem.Used())
+			mu.Unlock()
+			time.Sleep(time.Millisecond * 10)
+		}(i, mb)
+	}
+	wg.Wait()
+	fmt.Printf("  ran %d jobs, peak memory %d of %d MB\n", len(jobs), peak, capacityMB)
+
+	// A request that cannot fit fails at once rather than blocking
+	// forever, and so does one for no weight
+	fmt.Println("  Error:", sem.Acquire(context.Background(), 20))
+	fmt.Println("  Error:", sem.Acquire(context.Background(), 0))
+
+	// A waiter that times out leaves the queue
+	if err := sem.Acquire(context.Background(), 8); err != nil {
+		fmt.Println("Error:", err)
+		return
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), time.Millisecond*10)
+	defer cancel()
+	err = sem.Acquire(ctx, 5)
+	ok, tryErr := sem.TryAcquire(2)
+	fmt.Println("  timed out:", errors.Is(err, context.DeadlineExceeded), "try 2MB:", ok, tryErr)
+
+	// Giving back more than is held is a bug in the caller
+	fmt.Println("  Error:", sem.Release(20))
+	_, err = NewSemaphore(0)
+	fmt.Println("  Error:", err)
+}
+
+func main() {
+	fmt.Println("Coordination Primitives in Go:")
+
+	fmt.Println("\n=== Cyclic Barrier ===")
+	barrierExample()
+
+	fmt.Println("\n=== Broken Barrier ===")
+	brokenBarrierExample()
+
+	fmt.Println("\n=== Phaser ===")
+	phaserExample()
+
+	fmt.Println("\n=== Countdown Latch ===")
+	latchExample()
+
+	fmt.Println("\n=== Weighted Semaphore ===")
+	semaphoreExample()
+
+	fmt.Println("\nCoordination primitives examples completed")
+}
@@ -0,0 +1,149 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"sync"
+)
+
+// ErrPhaserTerminated is returned once every party has deregistered
+var ErrPhaserTerminated = errors.New("phaser terminated")
+
+// ErrNoUnarrived is returned by Arrive and ArriveAndDeregister when every
+// registered party has already arrived at the current phase, which means
+// a caller arrived that was never registered
+var ErrNoUnarrived = errors.New("no unarrived parties in this phase")
+
+// Phaser is a reusable barrier whose number of parties can change between
+// phases. Each phase ends when every registered party has arrived.
+type Phaser struct {
+	mu         sync.Mutex
+	phase      int
+	parties    int
+	arrived    int
+	done       chan struct{} // Closed when the current phase ends
+	terminated bool
+	onAdvance  func(phase, parties int) // Optional; runs under the lock, so must not call the phaser
+}
+
+// Constructor for a phaser with an initial number of parties
+func NewPhaser(parties int, onAdvance func(phase, parties int)) *Phaser {
+	if parties < 0 {
+		panic("phaser cannot have negative parties")
+	}
+	return &Phaser{parties: parties, done: make(chan struct{}), onAdvance: onAdvance}
+}
+
+// Register adds a party, which takes part from the current phase on. It
+// returns that phase.
+func (p *Phaser) Register() (int, error) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if p.terminated {
+		return 0, ErrPhaserTerminated
+	}
+	p.parties++
+	return p.phase, nil
+}
+
+// Arrive marks one party as done with the current phase without waiting
+// for the others. It returns the phase arrived at.
+func (p *Phaser) Arrive() (int, error) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if p.terminated {
+		return 0, ErrPhaserTerminated
+	}
+	if p.arrived >= p.parties {
+		return 0, ErrNoUnarrived
+	}
+	phase := p.phase
+	p.arrived++
+	p.advanceIfDoneLocked()
+	return phase, nil
+}
+
+// ArriveAndDeregister arrives and leaves the phaser. When the last party
+// leaves, the phaser terminates.
+func (p *Phaser) ArriveAndDeregister() (int, error) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if p.terminated {
+		return 0, ErrPhaserTerminated
+	}
+	if p.arrived >= p.parties {
+		return 0, ErrNoUnarrived
+	}
+	phase := p.phase
+	p.parties--
+	p.advanceIfDoneLocked()
+	return phase, nil
+}
+
+// ArriveAndAwait arrives and waits for the others, returning the new phase.
+// If ctx ends first it returns ctx's error; the arrival still counts, as
+// the caller has finished its part of the phase.
+func (p *Phaser) ArriveAndAwait(ctx context.Context) (int, error) {
+	phase, err := p.Arrive()
+	if err != nil {
+		return 0, err
+	}
+	return p.AwaitAdvance(ctx, phase)
+}
+
+// AwaitAdvance waits until the given phase has ended and returns the
+// phase after it. It returns at once if that phase is already over.
+func (p *Phaser) AwaitAdvance(ctx context.Context, phase int) (int, error) {
+	p.mu.Lock()
+	if p.phase != phase {
+		current := p.phase
+		p.mu.Unlock()
+		return current, nil
+	}
+	done := p.done
+	p.mu.Unlock()
+
+	select {
+	case <-done:
+		p.mu.Lock()
+		defer p.mu.Unlock()
+		if p.terminated {
+			return phase + 1, ErrPhaserTerminated
+		}
+		return phase + 1, nil
+	case <-ctx.Done():
+		return phase, ctx.Err()
+	}
+}
+
+// advanceIfDoneLocked ends the phase once every party has arrived;
+// p.mu must be held
+func (p *Phaser) advanceIfDoneLocked() {
+	if p.arrived < p.parties {
+		return
+	}
+	if p.onAdvance != nil {
+		p.onAdvance(p.phase, p.parties)
+	}
+	if p.parties == 0 {
+		p.terminated = true
+	}
+	close(p.done)
+	p.done = make(chan struct{})
+	p.phase++
+	p.arrived = 0
+}
+
+// Phase returns the current phase number
+func (p *Phaser) Phase() int {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	return p.phase
+}
+
+// Parties returns the number of registered parties
+func (p *Phaser) Parties() int {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	return p.parties
+}
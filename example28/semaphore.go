@@ -0,0 +1,128 @@
+package main
+
+import (
+	"container/list"
+	"context"
+	"fmt"
+	"sync"
+)
+
+// semWaiter is one blocked Acquire
+type semWaiter struct {
+	n     int64
+	ready chan struct{} // Closed once the weight has been granted
+}
+
+// Semaphore limits the total weight held at once, for example bytes of
+// memory rather than a number of goroutines. Waiters are served in order,
+// so a large request is not starved by a stream of small ones.
+type Semaphore struct {
+	mu      sync.Mutex
+	size    int64
+	used    int64
+	waiters list.List // Of *semWaiter
+}
+
+// Constructor for a semaphore with the given total weight, which must be
+// positive
+func NewSemaphore(size int64) (*Semaphore, error) {
+	if size <= 0 {
+		return nil, fmt.Errorf("semaphore size %d must be positive", size)
+	}
+	return &Semaphore{size: size}, nil
+}
+
+// Acquire blocks until weight n is available or ctx ends. n must be
+// positive: a zero or negative weight would let Release grow the semaphore.
+func (s *Semaphore) Acquire(ctx context.Context, n int64) error {
+	if n <= 0 {
+		return fmt.Errorf("acquire %d: weight must be positive", n)
+	}
+	s.mu.Lock()
+	if n > s.size {
+		s.mu.Unlock()
+		return fmt.Errorf("acquire %d: more than the semaphore size %d", n, s.size)
+	}
+	if s.size-s.used >= n && s.waiters.Len() == 0 {
+		s.used += n
+		s.mu.Unlock()
+		return nil
+	}
+
+	w := &semWaiter{n: n, ready: make(chan struct{})}
+	elem := s.waiters.PushBack(w)
+	s.mu.Unlock()
+
+	select {
+	case <-w.ready:
+		return nil
+	case <-ctx.Done():
+		s.mu.Lock()
+		defer s.mu.Unlock()
+		select {
+		case <-w.ready:
+			// Granted while we were cancelled; give it back
+			s.used -= n
+		default:
+			s.waiters.Remove(elem)
+		}
+		// Leaving the front of the queue may let the next waiter in
+		s.notifyLocked()
+		return ctx.Err()
+	}
+}
+
+// TryAcquire takes weight n if it is available now, without waiting. Like
+// Acquire, it returns an error if n is not positive.
+func (s *Semaphore) TryAcquire(n int64) (bool, error) {
+	if n <= 0 {
+		return false, fmt.Errorf("try acquire %d: weight must be positive", n)
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.size-s.used >= n && s.waiters.Len() == 0 {
+		s.used += n
+		return true, nil
+	}
+	return false, nil
+}
+
+// Release gives back weight n. It returns an error, and releases nothing,
+// if n is not positive or is more than is held.
+func (s *Semaphore) Release(n int64) error {
+	if n <= 0 {
+		return fmt.Errorf("release %d: weight must be positive", n)
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if n > s.used {
+		return fmt.Errorf("release %d: more than the %d held", n, s.used)
+	}
+	s.used -= n
+	s.notifyLocked()
+	return nil
+}
+
+// notifyLocked grants waiting requests in order while they fit; s.mu must be held
+func (s *Semaphore) notifyLocked() {
+	for {
+		front := s.waiters.Front()
+		if front == nil {
+			return
+		}
+		w := front.Value.(*semWaiter)
+		if s.size-s.used < w.n {
+			return // Keep the order: later waiters wait behind this one
+		}
+		s.used += w.n
+		s.waiters.Remove(front)
+		close(w.ready)
+	}
+}
+
+// Used returns the weight currently held
+func (s *Semaphore) Used() int64 {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.used
+}
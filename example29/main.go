@@ -0,0 +1,204 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"io"
+	"math/rand"
+	"os"
+	"runtime"
+	"sort"
+	"text/tabwriter"
+	"time"
+)
+
+// Example 29: Parallel Algorithms
+// Demonstrates parallel merge sort, quicksort and prefix scan, and
+// benchmarks the sorts against sort.Slice
+
+// Record is one row of a report
+type Record struct {
+	ID       int
+	Customer string
+	Cents    int64
+}
+
+// Function that orders records by amount, then ID, as reports list them
+func byAmount(a, b Record) bool {
+	if a.Cents != b.Cents {
+		return a.Cents < b.Cents
+	}
+	return a.ID < b.ID
+}
+
+// Function that generates n records with a fixed seed
+func makeRecords(n int) []Record {
+	rng := rand.New(rand.NewSource(42))
+	customers := []string{"Acme", "Globex", "Initech", "Umbrella", "Hooli"}
+	records := make([]Record, n)
+	for i := range records {
+		records[i] = Record{
+			ID:       i,
+			Customer: customers[rng.Intn(len(customers))],
+			Cents:    rng.Int63n(1_000_000),
+		}
+	}
+	return records
+}
+
+// sorter is one implementation under benchmark
+type sorter struct {
+	name string
+	sort func(records []Record, opts Options)
+}
+
+var sorters = []sorter{
+	{"sort.Slice", func(r []Record, _ Options) {
+		sort.Slice(r, func(i, j int) bool { return byAmount(r[i], r[j]) })
+	}},
+	{"sort.SliceStable", func(r []Record, _ Options) {
+		sort.SliceStable(r, func(i, j int) bool { return byAmount(r[i], r[j]) })
+	}},
+	{"MergeSort", func(r []Record, opts Options) { MergeSort(r, byAmount, opts) }},
+	{"QuickSort", func(r []Record, opts Options) { QuickSort(r, byAmount, opts) }},
+}
+
+// Function that times one sort on a fresh copy of the input and checks
+// the result
+func measure(s sorter, input []Record, opts Options) (time.Duration, error) {
+	records := make([]Record, len(input))
+	copy(records, input)
+
+	start := time.Now()
+	s.sort(records, opts)
+	elapsed := time.Since(start)
+
+	for i := 1; i < len(records); i++ {
+		if byAmount(records[i], records[i-1]) {
+			return 0, fmt.Errorf("%s: records %d and %d out of order", s.name, i-1, i)
+		}
+	}
+	return elapsed, nil
+}
+
+// Function that runs every sorter at every worker count, keeping the
+// fastest of several runs
+func benchmark(input []Record, workerCounts []int, cutoff, runs int) ([][]time.Duration, error) {
+	table := make([][]time.Duration, len(sorters))
+	for i, s := range sorters {
+		table[i] = make([]time.Duration, len(workerCounts))
+		for j, workers := range workerCounts {
+			for r := 0; r < runs; r++ {
+				elapsed, err := measure(s, input, Options{Workers: workers, Cutoff: cutoff})
+				if err != nil {
+					return nil, err
+				}
+				if table[i][j] == 0 || elapsed < table[i][j] {
+					table[i][j] = elapsed
+				}
+			}
+		}
+	}
+	return table, nil
+}
+
+// Function that prints the benchmark as a table, relative to sort.Slice
+// with one worker
+func printReport(w io.Writer, workerCounts []int, table [][]time.Duration) {
+	baseline := table[0][0]
+	tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', tabwriter.AlignRight)
+	fmt.Fprint(tw, "\t")
+	for _, workers := range workerCounts {
+		fmt.Fprintf(tw, "%d workers\t", workers)
+	}
+	fmt.Fprintln(tw)
+	for i, s := range sorters {
+		fmt.Fprintf(tw, "%s\t", s.name)
+		for j := range workerCounts {
+			fmt.Fprintf(tw, "%v (%.2fx)\t", table[i][j].Round(time.Microsecond),
+				float64(baseline)/float64(table[i][j]))
+		}
+		fmt.Fprintln(tw)
+	}
+	tw.Flush()
+}
+
+// Function that shows the scan computing running totals
+func scanExample(records []Record, workers, cutoff int) error {
+	amounts := make([]int64, len(records))
+	for i, r := range records {
+		amounts[i] = r.Cents
+	}
+	add := func(a, b int64) int64 { return a + b }
+
+	start := time.Now()
+	want := make([]int64, len(amounts))
+	scanBlock(amounts, want, add)
+	sequential := time.Since(start)
+
+	start = time.Now()
+	totals := Scan(amounts, add, Options{Workers: workers, Cutoff: cutoff})
+	parallel := time.Since(start)
+
+	for i := range want {
+		if totals[i] != want[i] {
+			return fmt.Errorf("scan differs at %d: got %d, want %d", i, totals[i], want[i])
+		}
+	}
+	total := want[len(want)-1]
+	fmt.Printf("Running total of %d amounts: $%d.%02d\n", len(amounts), total/100, total%100)
+	fmt.Printf("Sequential %v, parallel with %d workers %v\n",
+		sequential.Round(time.Microsecond), workers, parallel.Round(time.Microsecond))
+
+	// Any associative operation works, such as a running maximum
+	first := amounts[:min(8, len(amounts))]
+	peaks := Scan(first, func(a, b int64) int64 { return max(a, b) }, Options{Workers: workers, Cutoff: 2})
+	fmt.Println("Amounts:     ", first)
+	fmt.Println("Running max: ", peaks)
+	return nil
+}
+
+func main() {
+	n := flag.Int("n", 500_000, "number of records to sort")
+	cutoff := flag.Int("cutoff", DefaultCutoff, "slices shorter than this are sorted sequentially")
+	runs := flag.Int("runs", 3, "runs per measurement; the fastest is reported")
+	flag.Parse()
+
+	if *n < 1 {
+		fmt.Println("Error: -n must be at least 1")
+		os.Exit(2)
+	}
+
+	fmt.Println("Parallel Algorithms in Go:")
+	records := makeRecords(*n)
+
+	fmt.Println("\n=== Stability ===")
+	small := makeRecords(12)
+	for i := range small {
+		small[i].Cents = small[i].Cents % 3 * 100 // Many equal amounts
+	}
+	byAmountOnly := func(a, b Record) bool { return a.Cents < b.Cents }
+	MergeSort(small, byAmountOnly, Options{Workers: 4, Cutoff: 2})
+	for _, r := range small {
+		fmt.Printf("%d:%d ", r.Cents, r.ID)
+	}
+	fmt.Println("\nEqual amounts keep their ID order: merge sort is stable")
+
+	fmt.Println("\n=== Prefix Scan ===")
+	if err := scanExample(records, runtime.GOMAXPROCS(0), *cutoff); err != nil {
+		fmt.Println("Error:", err)
+		os.Exit(1)
+	}
+
+	fmt.Println("\n=== Sort Benchmark ===")
+	workerCounts := []int{1, 2, 4, 8}
+	fmt.Printf("GOMAXPROCS=%d, %d records, cutoff %d, best of %d runs\n\n", runtime.GOMAXPROCS(0), *n, *cutoff, *runs)
+	table, err := benchmark(records, workerCounts, *cutoff, *runs)
+	if err != nil {
+		fmt.Println("Error:", err)
+		os.Exit(1)
+	}
+	printReport(os.Stdout, workerCounts, table)
+
+	fmt.Println("\nParallel algorithm examples completed")
+}
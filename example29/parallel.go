@@ -0,0 +1,258 @@
+package main
+
+import (
+	"runtime"
+	"slices"
+	"sync"
+)
+
+// Options controls how the parallel algorithms split their work
+type Options struct {
+	// Most goroutines working at once; defaults to GOMAXPROCS
+	Workers int
+	// Slices shorter than this are handled sequentially, as a goroutine
+	// costs more than sorting a few thousand elements
+	Cutoff int
+}
+
+// DefaultCutoff is used when Options.Cutoff is not set
+const DefaultCutoff = 4096
+
+func (o Options) withDefaults() Options {
+	if o.Workers <= 0 {
+		o.Workers = runtime.GOMAXPROCS(0)
+	}
+	if o.Cutoff <= 0 {
+		o.Cutoff = DefaultCutoff
+	}
+	// A single element cannot be split any further
+	o.Cutoff = max(o.Cutoff, 2)
+	return o
+}
+
+// spawner runs tasks in new goroutines while fewer than the worker limit
+// are running, and in the caller's goroutine otherwise, so recursion never
+// waits for a free worker
+type spawner struct {
+	tokens chan struct{}
+	wg     sync.WaitGroup
+}
+
+func newSpawner(workers int) *spawner {
+	// The calling goroutine is one of the workers
+	return &spawner{tokens: make(chan struct{}, workers-1)}
+}
+
+func (s *spawner) run(task func()) {
+	select {
+	case s.tokens <- struct{}{}:
+		s.wg.Add(1)
+		go func() {
+			defer s.wg.Done()
+			defer func() { <-s.tokens }()
+			task()
+		}()
+	default:
+		task()
+	}
+}
+
+// both runs a and b, in parallel when a worker is free, and returns when
+// both are done
+func (s *spawner) both(a, b func()) {
+	var wg sync.WaitGroup
+	wg.Add(1)
+	s.run(func() {
+		defer wg.Done()
+		a()
+	})
+	b()
+	wg.Wait()
+}
+
+// cmpFunc adapts a less function to the three-way form slices expects
+func cmpFunc[T any](less func(a, b T) bool) func(a, b T) int {
+	return func(a, b T) int {
+		switch {
+		case less(a, b):
+			return -1
+		case less(b, a):
+			return 1
+		}
+		return 0
+	}
+}
+
+// MergeSort sorts s stably, sorting the two halves of each range in
+// parallel and then merging them
+func MergeSort[T any](s []T, less func(a, b T) bool, opts Options) {
+	opts = opts.withDefaults()
+	if len(s) < opts.Cutoff {
+		slices.SortStableFunc(s, cmpFunc(less))
+		return
+	}
+	buf := make([]T, len(s))
+	mergeSort(newSpawner(opts.Workers), s, buf, less, opts.Cutoff)
+}
+
+func mergeSort[T any](sp *spawner, s, buf []T, less func(a, b T) bool, cutoff int) {
+	if len(s) < cutoff {
+		slices.SortStableFunc(s, cmpFunc(less))
+		return
+	}
+	mid := len(s) / 2
+	sp.both(
+		func() { mergeSort(sp, s[:mid], buf[:mid], less, cutoff) },
+		func() { mergeSort(sp, s[mid:], buf[mid:], less, cutoff) },
+	)
+
+	// Already in order, as often happens with nearly sorted input
+	if !less(s[mid], s[mid-1]) {
+		return
+	}
+	merge(s[:mid], s[mid:], buf[:len(s)], less)
+	copy(s, buf[:len(s)])
+}
+
+// merge writes the merge of sorted a and b to out. Ties take from a, which
+// keeps the sort stable.
+func merge[T any](a, b, out []T, less func(a, b T) bool) {
+	i, j, k := 0, 0, 0
+	for i < len(a) && j < len(b) {
+		if less(b[j], a[i]) {
+			out[k] = b[j]
+			j++
+		} else {
+			out[k] = a[i]
+			i++
+		}
+		k++
+	}
+	k += copy(out[k:], a[i:])
+	copy(out[k:], b[j:])
+}
+
+// QuickSort sorts s in place, partitioning around a median-of-three pivot
+// and sorting the two parts in parallel. It is not stable.
+func QuickSort[T any](s []T, less func(a, b T) bool, opts Options) {
+	opts = opts.withDefaults()
+	sp := newSpawner(opts.Workers)
+	quickSort(sp, s, less, opts.Cutoff)
+	sp.wg.Wait()
+}
+
+func quickSort[T any](sp *spawner, s []T, less func(a, b T) bool, cutoff int) {
+	for len(s) >= cutoff {
+		p := partition(s, less)
+		left, right := s[:p+1], s[p+1:]
+
+		// Hand the smaller part off and keep looping on the larger, which
+		// bounds the recursion depth
+		if len(left) > len(right) {
+			left, right = right, left
+		}
+		small := left
+		sp.run(func() { quickSort(sp, small, less, cutoff) })
+		s = right
+	}
+	slices.SortFunc(s, cmpFunc(less))
+}
+
+// partition is Hoare's scheme. It returns p such that every element of
+// s[:p+1] is no greater than every element of s[p+1:], with both non-empty.
+func partition[T any](s []T, less func(a, b T) bool) int {
+	// Median of three, moved to the middle
+	lo, mid, hi := 0, (len(s)-1)/2, len(s)-1 // mid must round down for Hoare
+	if less(s[mid], s[lo]) {
+		s[mid], s[lo] = s[lo], s[mid]
+	}
+	if less(s[hi], s[lo]) {
+		s[hi], s[lo] = s[lo], s[hi]
+	}
+	if less(s[hi], s[mid]) {
+		s[hi], s[mid] = s[mid], s[hi]
+	}
+	pivot := s[mid]
+
+	i, j := -1, len(s)
+	for {
+		for i++; less(s[i], pivot); i++ {
+		}
+		for j--; less(pivot, s[j]); j-- {
+		}
+		if i >= j {
+			return j
+		}
+		s[i], s[j] = s[j], s[i]
+	}
+}
+
+// Scan returns the inclusive prefix scan of s: out[i] is s[0] op s[1] op
+// ... op s[i]. op must be associative, like + or max, as the blocks are
+// combined in a different grouping than a sequential loop would use.
+func Scan[T any](s []T, op func(a, b T) T, opts Options) []T {
+	opts = opts.withDefaults()
+	out := make([]T, len(s))
+	if len(s) == 0 {
+		return out
+	}
+	blocks := min(opts.Workers, (len(s)+opts.Cutoff-1)/opts.Cutoff)
+	if blocks <= 1 {
+		scanBlock(s, out, op)
+		return out
+	}
+	size := (len(s) + blocks - 1) / blocks
+	// Rounding size up can leave fewer blocks than asked for; an empty
+	// last block would have nothing to scan
+	blocks = (len(s) + size - 1) / size
+	bounds := func(b int) (int, int) {
+		return b * size, min((b+1)*size, len(s))
+	}
+
+	// Phase 1: scan each block on its own
+	parallelBlocks(blocks, func(b int) {
+		lo, hi := bounds(b)
+		scanBlock(s[lo:hi], out[lo:hi], op)
+	})
+
+	// Phase 2: the total of every block before each one, sequentially
+	offsets := make([]T, blocks)
+	for b := 1; b < blocks; b++ {
+		_, prevHi := bounds(b - 1)
+		if b == 1 {
+			offsets[b] = out[prevHi-1]
+		} else {
+			offsets[b] = op(offsets[b-1], out[prevHi-1])
+		}
+	}
+
+	// Phase 3: fold the offsets into every block but the first
+	parallelBlocks(blocks-1, func(b int) {
+		lo, hi := bounds(b + 1)
+		for i := lo; i < hi; i++ {
+			out[i] = op(offsets[b+1], out[i])
+		}
+	})
+	return out
+}
+
+// scanBlock is the sequential inclusive scan of in into out
+func scanBlock[T any](in, out []T, op func(a, b T) T) {
+	out[0] = in[0]
+	for i := 1; i < len(in); i++ {
+		out[i] = op(out[i-1], in[i])
+	}
+}
+
+// parallelBlocks runs fn for every block index in its own goroutine
+func parallelBlocks(blocks int, fn func(b int)) {
+	var wg sync.WaitGroup
+	for b := 0; b < blocks; b++ {
+		wg.Add(1)
+		go func(b int) {
+			defer wg.Done()
+			fn(b)
+		}(b)
+	}
+	wg.Wait()
+}
@@ -0,0 +1,124 @@
+package main
+
+import (
+	"fmt"
+	"slices"
+	"sort"
+	"testing"
+)
+
+func TestSorts(t *testing.T) {
+	less := func(a, b int) bool { return a < b }
+	for _, n := range []int{0, 1, 2, 9, 100, 10007} {
+		for _, cutoff := range []int{1, 2, 3, 64} {
+			input := make([]int, n)
+			for i := range input {
+				input[i] = (i * 7919) % 13 // Many duplicates
+			}
+			want := slices.Clone(input)
+			slices.Sort(want)
+
+			merged := slices.Clone(input)
+			MergeSort(merged, less, Options{Workers: 4, Cutoff: cutoff})
+			if !slices.Equal(merged, want) {
+				t.Errorf("MergeSort n=%d cutoff=%d: not sorted", n, cutoff)
+			}
+			quick := slices.Clone(input)
+			QuickSort(quick, less, Options{Workers: 4, Cutoff: cutoff})
+			if !slices.Equal(quick, want) {
+				t.Errorf("QuickSort n=%d cutoff=%d: not sorted", n, cutoff)
+			}
+		}
+	}
+}
+
+func TestMergeSortStable(t *testing.T) {
+	records := makeRecords(5000)
+	for i := range records {
+		records[i].Cents %= 10
+	}
+	MergeSort(records, func(a, b Record) bool { return a.Cents < b.Cents }, Options{Workers: 4, Cutoff: 16})
+	for i := 1; i < len(records); i++ {
+		if records[i].Cents == records[i-1].Cents && records[i].ID < records[i-1].ID {
+			t.Fatalf("records %d and %d with equal amounts swapped", i-1, i)
+		}
+	}
+}
+
+func TestScan(t *testing.T) {
+	add := func(a, b int) int { return a + b }
+	for _, n := range []int{0, 1, 2, 9, 10, 1000} {
+		for _, workers := range []int{1, 3, 4, 8} {
+			for _, cutoff := range []int{1, 2, 3, 100} {
+				input := make([]int, n)
+				for i := range input {
+					input[i] = i + 1
+				}
+				got := Scan(input, add, Options{Workers: workers, Cutoff: cutoff})
+				sum := 0
+				for i, v := range input {
+					sum += v
+					if got[i] != sum {
+						t.Fatalf("n=%d workers=%d cutoff=%d: out[%d] = %d, want %d", n, workers, cutoff, i, got[i], sum)
+					}
+				}
+			}
+		}
+	}
+}
+
+// benchmarkSort runs sortFn on a fresh copy of the same records each iteration
+func benchmarkSort(b *testing.B, sortFn func([]Record)) {
+	input := makeRecords(200_000)
+	records := make([]Record, len(input))
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		b.StopTimer()
+		copy(records, input)
+		b.StartTimer()
+		sortFn(records)
+	}
+}
+
+func BenchmarkSortSlice(b *testing.B) {
+	benchmarkSort(b, func(r []Record) {
+		sort.Slice(r, func(i, j int) bool { return byAmount(r[i], r[j]) })
+	})
+}
+
+func BenchmarkSortSliceStable(b *testing.B) {
+	benchmarkSort(b, func(r []Record) {
+		sort.SliceStable(r, func(i, j int) bool { return byAmount(r[i], r[j]) })
+	})
+}
+
+func BenchmarkMergeSort(b *testing.B) {
+	for _, workers := range []int{1, 2, 4, 8} {
+		b.Run(fmt.Sprintf("workers=%d", workers), func(b *testing.B) {
+			benchmarkSort(b, func(r []Record) { MergeSort(r, byAmount, Options{Workers: workers}) })
+		})
+	}
+}
+
+func BenchmarkQuickSort(b *testing.B) {
+	for _, workers := range []int{1, 2, 4, 8} {
+		b.Run(fmt.Sprintf("workers=%d", workers), func(b *testing.B) {
+			benchmarkSort(b, func(r []Record) { QuickSort(r, byAmount, Options{Workers: workers}) })
+		})
+	}
+}
+
+func BenchmarkScan(b *testing.B) {
+	input := make([]int64, 1_000_000)
+	for i := range input {
+		input[i] = int64(i)
+	}
+	add := func(a, b int64) int64 { return a + b }
+	for _, workers := range []int{1, 2, 4, 8} {
+		b.Run(fmt.Sprintf("workers=%d", workers), func(b *testing.B) {
+			for i := 0; i < b.N; i++ {
+				Scan(input, add, Options{Workers: workers})
+			}
+		})
+	}
+}
@@ -1,36 +1,39 @@
 package main
 
 import (
+	"errors"
 	"fmt"
+	"reflect"
 	"time"
+	"unicode"
 )
 
 // Example 7: Structs
 // Demonstrates working with structs (custom data types) in Go
 
-// Basic struct definition
+// Basic struct definition. The validate tags are checked by Validate.
 type Person struct {
-	FirstName string
-	LastName  string
-	Age       int
+	FirstName string `validate:"required,max=50"`
+	LastName  string `validate:"required,max=50"`
+	Age       int    `validate:"min=0,max=150"`
 }
 
 // Struct with embedded struct
 type Employee struct {
-	Person     // Embedded struct
-	EmployeeID string
-	Title      string
-	Department string
-	HireDate   time.Time
+	Person               // Embedded struct
+	EmployeeID string    `validate:"required,regex=^E[0-9]{5}$"`
+	Title      string    `validate:"required,capitalized"`
+	Department string    `validate:"oneof=Engineering Sales Marketing Support"`
+	HireDate   time.Time `validate:"required"`
 }
 
 // Struct with tags for serialization
 type Product struct {
-	ID          int     `json:"id"`
-	Name        string  `json:"name"`
-	Description string  `json:"description,omitempty"`
-	Price       float64 `json:"price"`
-	SKU         string  `json:"sku,omitempty"`
+	ID          int     `json:"id" validate:"positive"`
+	Name        string  `json:"name" validate:"required,max=100"`
+	Description string  `json:"description,omitempty" validate:"max=500"`
+	Price       float64 `json:"price" validate:"positive"`
+	SKU         string  `json:"sku,omitempty" validate:"sku"`
 }
 
 // Method for Person struct
@@ -133,4 +136,55 @@ func main() {
 	// No need to dereference when accessing fields
 	pPtr.Age = 41 // Same as (*pPtr).Age = 41
 	fmt.Println("After modifying age:", pPtr)
+
+	// Validation with struct tags
+	fmt.Println("\nValidation with struct tags:")
+
+	// A custom rule, used by Employee.Title
+	RegisterRule("capitalized", func(v reflect.Value, _ string) error {
+		s := v.String()
+		if s == "" || !unicode.IsUpper([]rune(s)[0]) {
+			return errors.New("must start with a capital letter")
+		}
+		return nil
+	})
+
+	fmt.Println("Employee valid:", Validate(employee) == nil)
+	fmt.Println("Person 3:", Validate(p3))
+
+	bad := Employee{
+		Person:     Person{FirstName: "Tom", Age: 200},
+		EmployeeID: "12345",
+		Title:      "intern",
+		Department: "Legal",
+	}
+	var violations ValidationErrors
+	if errors.As(Validate(bad), &violations) {
+		for _, v := range violations {
+			fmt.Printf("  %-26s %-11s %s\n", v.Field, v.Rule, v.Message)
+		}
+	}
+
+	products := []Product{
+		{ID: 1, Name: "Laptop", Price: 999.99, SKU: "ELC-10042"},
+		{ID: 2, Name: "Cable", Price: 4.5}, // SKU is optional
+		{ID: 0, Name: "", Price: -1, SKU: "cable"},
+	}
+	for _, product := range products {
+		if err := Validate(product); err != nil {
+			fmt.Printf("Product %d invalid: %v\n", product.ID, err)
+		} else {
+			fmt.Printf("Product %d valid\n", product.ID)
+		}
+	}
+
+	// A mistake in a tag is a bug in the code, not bad data
+	type Order struct {
+		Count int    `validate:"min=abc"`
+		Code  string `validate:"positive"`
+	}
+	err := Validate(Order{Count: 1, Code: "x"})
+	var tagErr *TagError
+	fmt.Println("Misconfigured tag:", err)
+	fmt.Println("Is a tag error:", errors.As(err, &tagErr), "- is a violation:", errors.As(err, &violations))
 }
\ No newline at end of file
@@ -0,0 +1,326 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"reflect"
+	"regexp"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Validation driven by struct tags, for example:
+//
+//	Age int `validate:"min=0,max=150"`
+//
+// Rules are separated by commas and take an optional parameter after "=",
+// so a parameter cannot itself contain a comma. Rules other than required
+// skip empty strings, so optional fields only need checking when set.
+// Nested and embedded structs are checked too, with paths like
+// Employee.Person.Age.
+
+// Custom error type, in the style of example 10
+type ValidationError struct {
+	Field   string
+	Rule    string
+	Message string
+}
+
+// Implementation of the Error interface for custom error type
+func (e ValidationError) Error() string {
+	return fmt.Sprintf("validation error on field %s: %s", e.Field, e.Message)
+}
+
+// ValidationErrors is every rule a value broke
+type ValidationErrors []ValidationError
+
+func (errs ValidationErrors) Error() string {
+	messages := make([]string, len(errs))
+	for i, e := range errs {
+		messages[i] = e.Error()
+	}
+	return strings.Join(messages, "; ")
+}
+
+// Unwrap lets errors.As find each ValidationError
+func (errs ValidationErrors) Unwrap() []error {
+	result := make([]error, len(errs))
+	for i, e := range errs {
+		result[i] = e
+	}
+	return result
+}
+
+// ErrBadTag marks a rule error as a mistake in the tag rather than in the
+// data, such as min=abc or regex on an int field. Rules wrap it with %w.
+var ErrBadTag = errors.New("bad tag")
+
+// TagError reports a malformed validate tag. It is a bug in the struct
+// definition, so Validate returns it on its own instead of as a violation.
+type TagError struct {
+	Field string
+	Rule  string
+	Err   error
+}
+
+func (e *TagError) Error() string {
+	return fmt.Sprintf("validate: field %s: %v", e.Field, e.Err)
+}
+
+func (e *TagError) Unwrap() error { return e.Err }
+
+// Function that builds a rule error for a malformed tag
+func badTag(format string, args ...any) error {
+	return fmt.Errorf("%w: "+format, append([]any{ErrBadTag}, args...)...)
+}
+
+// RuleFunc checks one value against a rule's parameter. It returns nil if
+// the value passes, or an error whose text becomes the violation message.
+// An error wrapping ErrBadTag means the tag itself is wrong.
+type RuleFunc func(value reflect.Value, param string) error
+
+var (
+	rulesMu sync.RWMutex
+	rules   = map[string]RuleFunc{
+		"required": ruleRequired,
+		"min":      ruleMin,
+		"max":      ruleMax,
+		"positive": rulePositive,
+		"len":      ruleLen,
+		"regex":    ruleRegex,
+		"oneof":    ruleOneOf,
+		"sku":      ruleSKU,
+	}
+)
+
+// RegisterRule adds a rule that tags can use by name, replacing any rule
+// of the same name
+func RegisterRule(name string, fn RuleFunc) {
+	rulesMu.Lock()
+	defer rulesMu.Unlock()
+	rules[name] = fn
+}
+
+func lookupRule(name string) (RuleFunc, bool) {
+	rulesMu.RLock()
+	defer rulesMu.RUnlock()
+	fn, ok := rules[name]
+	return fn, ok
+}
+
+// Validate checks every validate tag in x, a struct or pointer to one. It
+// returns ValidationErrors listing every violation in the data, or a
+// *TagError if a tag is malformed.
+func Validate(x any) error {
+	v := reflect.ValueOf(x)
+	for v.Kind() == reflect.Pointer {
+		if v.IsNil() {
+			return errors.New("validate: nil pointer")
+		}
+		v = v.Elem()
+	}
+	if v.Kind() != reflect.Struct {
+		return fmt.Errorf("validate: %s is not a struct", v.Type())
+	}
+
+	var violations ValidationErrors
+	if err := validateStruct(v, v.Type().Name(), &violations); err != nil {
+		return err
+	}
+	if len(violations) > 0 {
+		return violations
+	}
+	return nil
+}
+
+// validateStruct checks each field of a struct, then recurses into
+// nested structs
+func validateStruct(v reflect.Value, path string, violations *ValidationErrors) error {
+	t := v.Type()
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		if !field.IsExported() {
+			continue
+		}
+		value := v.Field(i)
+		fieldPath := path + "." + field.Name
+
+		if tag := field.Tag.Get("validate"); tag != "" && tag != "-" {
+			for _, rule := range strings.Split(tag, ",") {
+				name, param, _ := strings.Cut(rule, "=")
+				fn, ok := lookupRule(name)
+				if !ok {
+					return &TagError{Field: fieldPath, Rule: name, Err: badTag("unknown rule %q", name)}
+				}
+				if name != "required" && isEmptyString(value) {
+					continue
+				}
+				err := fn(value, param)
+				if errors.Is(err, ErrBadTag) {
+					return &TagError{Field: fieldPath, Rule: name, Err: err}
+				}
+				if err != nil {
+					*violations = append(*violations, ValidationError{Field: fieldPath, Rule: name, Message: err.Error()})
+				}
+			}
+		}
+
+		// Recurse into nested structs, but not into values like time.Time
+		if value.Kind() == reflect.Pointer && !value.IsNil() {
+			value = value.Elem()
+		}
+		if value.Kind() == reflect.Struct && value.Type() != reflect.TypeOf(time.Time{}) {
+			if err := validateStruct(value, fieldPath, violations); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+func isEmptyString(v reflect.Value) bool {
+	return v.Kind() == reflect.String && v.Len() == 0
+}
+
+// number returns a numeric field as a float64
+func number(v reflect.Value) (float64, bool) {
+	switch v.Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return float64(v.Int()), true
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return float64(v.Uint()), true
+	case reflect.Float32, reflect.Float64:
+		return v.Float(), true
+	}
+	return 0, false
+}
+
+// length returns the length of a string, in characters, or of a collection
+func length(v reflect.Value) (int, bool) {
+	switch v.Kind() {
+	case reflect.String:
+		return len([]rune(v.String())), true
+	case reflect.Slice, reflect.Array, reflect.Map:
+		return v.Len(), true
+	}
+	return 0, false
+}
+
+// Rule for a field that must be set: not zero, and not blank for strings
+func ruleRequired(v reflect.Value, _ string) error {
+	if v.Kind() == reflect.String && strings.TrimSpace(v.String()) == "" || v.IsZero() {
+		return errors.New("is required")
+	}
+	return nil
+}
+
+// bound implements min and max: a number's value or a length must be on
+// the right side of the parameter
+func bound(v reflect.Value, param, rule string, ok func(got, limit float64) bool, word string) error {
+	limit, err := strconv.ParseFloat(param, 64)
+	if err != nil {
+		return badTag("%s parameter %q is not a number", rule, param)
+	}
+	if n, isNumber := number(v); isNumber {
+		if !ok(n, limit) {
+			return fmt.Errorf("must be %s %s", word, param)
+		}
+		return nil
+	}
+	if n, hasLength := length(v); hasLength {
+		if !ok(float64(n), limit) {
+			return fmt.Errorf("length must be %s %s", word, param)
+		}
+		return nil
+	}
+	return badTag("%s does not apply to %s", rule, v.Type())
+}
+
+// Rule for a minimum value, or minimum length for strings and collections
+func ruleMin(v reflect.Value, param string) error {
+	return bound(v, param, "min", func(got, limit float64) bool { return got >= limit }, "at least")
+}
+
+// Rule for a maximum value, or maximum length for strings and collections
+func ruleMax(v reflect.Value, param string) error {
+	return bound(v, param, "max", func(got, limit float64) bool { return got <= limit }, "at most")
+}
+
+// Rule for a number above zero
+func rulePositive(v reflect.Value, _ string) error {
+	n, ok := number(v)
+	if !ok {
+		return badTag("positive does not apply to %s", v.Type())
+	}
+	if n <= 0 {
+		return errors.New("must be positive")
+	}
+	return nil
+}
+
+// Rule for an exact length
+func ruleLen(v reflect.Value, param string) error {
+	want, err := strconv.Atoi(param)
+	if err != nil {
+		return badTag("len parameter %q is not a number", param)
+	}
+	n, ok := length(v)
+	if !ok {
+		return badTag("len does not apply to %s", v.Type())
+	}
+	if n != want {
+		return fmt.Errorf("length must be %d", want)
+	}
+	return nil
+}
+
+// Compiled patterns, so each regex tag is compiled once
+var patterns sync.Map // string -> *regexp.Regexp
+
+// Rule for a string matching a regular expression
+func ruleRegex(v reflect.Value, param string) error {
+	if v.Kind() != reflect.String {
+		return badTag("regex does not apply to %s", v.Type())
+	}
+	re, ok := patterns.Load(param)
+	if !ok {
+		compiled, err := regexp.Compile(param)
+		if err != nil {
+			return badTag("regex parameter %q does not compile", param)
+		}
+		re, _ = patterns.LoadOrStore(param, compiled)
+	}
+	if !re.(*regexp.Regexp).MatchString(v.String()) {
+		return fmt.Errorf("must match %s", param)
+	}
+	return nil
+}
+
+// Rule for a value from a space-separated list
+func ruleOneOf(v reflect.Value, param string) error {
+	options := strings.Fields(param)
+	got := fmt.Sprint(v.Interface())
+	for _, option := range options {
+		if got == option {
+			return nil
+		}
+	}
+	return fmt.Errorf("must be one of %s", strings.Join(options, ", "))
+}
+
+// skuPattern is a stock keeping unit such as "ELC-10042": two to four
+// capital letters, a dash and four to eight digits
+var skuPattern = regexp.MustCompile(`^[A-Z]{2,4}-[0-9]{4,8}$`)
+
+// Rule for a product SKU
+func ruleSKU(v reflect.Value, _ string) error {
+	if v.Kind() != reflect.String {
+		return badTag("sku does not apply to %s", v.Type())
+	}
+	if !skuPattern.MatchString(v.String()) {
+		return errors.New("must be a SKU like ABC-1234")
+	}
+	return nil
+}
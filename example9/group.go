@@ -0,0 +1,132 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"runtime/debug"
+	"sync"
+)
+
+// Group runs goroutines like a WaitGroup, but also limits how many run at
+// once, collects their errors and turns panics into errors
+type Group struct {
+	ctx      context.Context
+	cancel   context.CancelCauseFunc
+	sem      chan struct{}
+	wg       sync.WaitGroup
+	failFast bool
+
+	mu   sync.Mutex
+	errs []error
+}
+
+// PanicError is returned for a goroutine that panicked. The message is
+// kept to one line; %+v adds the stack.
+type PanicError struct {
+	Value any
+	Stack []byte
+}
+
+func (e *PanicError) Error() string {
+	return fmt.Sprintf("goroutine panicked: %v", e.Value)
+}
+
+// Format prints the stack after the message for %+v, like errors from
+// example 10
+func (e *PanicError) Format(s fmt.State, verb rune) {
+	switch {
+	case verb == 'v' && s.Flag('+'):
+		io.WriteString(s, e.Error())
+		io.WriteString(s, "\n")
+		s.Write(e.Stack)
+	case verb == 'q':
+		fmt.Fprintf(s, "%q", e.Error())
+	default:
+		io.WriteString(s, e.Error())
+	}
+}
+
+// NewGroup creates a group that runs at most limit goroutines at a time
+// (no limit if limit <= 0). With failFast, the first error cancels the
+// returned context and is the one Wait reports; otherwise every goroutine
+// runs to completion and Wait reports all the errors together.
+func NewGroup(ctx context.Context, limit int, failFast bool) (*Group, context.Context) {
+	ctx, cancel := context.WithCancelCause(ctx)
+	g := &Group{ctx: ctx, cancel: cancel, failFast: failFast}
+	if limit > 0 {
+		g.sem = make(chan struct{}, limit)
+	}
+	return g, ctx
+}
+
+// Go runs fn in a new goroutine, blocking first while the group is at its
+// limit. In fail-fast mode fn is skipped once the group's context is done,
+// since the group has already failed.
+func (g *Group) Go(fn func() error) {
+	if g.sem != nil {
+		g.sem <- struct{}{}
+	}
+	if g.failFast && g.ctx.Err() != nil {
+		if g.sem != nil {
+			<-g.sem
+		}
+		// Wait reports the first error, so this only shows when the
+		// parent context was cancelled before anything failed
+		g.record(context.Cause(g.ctx))
+		return
+	}
+
+	g.wg.Add(1)
+	go func() {
+		defer g.wg.Done()
+		defer func() {
+			if g.sem != nil {
+				<-g.sem
+			}
+		}()
+
+		if err := g.run(fn); err != nil {
+			g.record(err)
+		}
+	}()
+}
+
+// run calls fn, recovering a panic as a PanicError
+func (g *Group) run(fn func() error) (err error) {
+	defer func() {
+		if r := recover(); r != nil {
+			err = &PanicError{Value: r, Stack: debug.Stack()}
+		}
+	}()
+	return fn()
+}
+
+// record stores an error and, in fail-fast mode, cancels the others
+func (g *Group) record(err error) {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+
+	g.errs = append(g.errs, err)
+	if g.failFast && len(g.errs) == 1 {
+		g.cancel(err)
+	}
+}
+
+// Wait blocks until every goroutine has returned. It returns the first
+// error in fail-fast mode, or all errors joined otherwise.
+func (g *Group) Wait() error {
+	g.wg.Wait()
+	g.cancel(nil) // Release the context's resources
+
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	if len(g.errs) == 0 {
+		return nil
+	}
+	if g.failFast {
+		return g.errs[0]
+	}
+	return errors.Join(g.errs...)
+}
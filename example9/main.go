@@ -1,6 +1,8 @@
 package main
 
 import (
+	"context"
+	"errors"
 	"fmt"
 	"sync"
 	"time"
@@ -10,13 +12,29 @@ import (
 // Demonstrates Go's concurrency features
 
 // Simple goroutine example
-func sayHello(id int, wg *sync.WaitGroup) {
-	// Defer the WaitGroup.Done() to ensure it's called when the function exits
-	defer wg.Done()
-
+func sayHello(ctx context.Context, id int) error {
 	fmt.Printf("Hello from goroutine %d\n", id)
-	time.Sleep(time.Millisecond * time.Duration(100*id))
+
+	// Sleep, but stop early if the group is cancelled
+	select {
+	case <-time.After(time.Millisecond * time.Duration(100*id)):
+	case <-ctx.Done():
+		fmt.Printf("Goroutine %d cancelled\n", id)
+		return ctx.Err()
+	}
+
 	fmt.Printf("Goodbye from goroutine %d\n", id)
+	return nil
+}
+
+// Goroutine that fails, to show how a Group reports errors
+func failAfter(ctx context.Context, id int, delay time.Duration) error {
+	select {
+	case <-time.After(delay):
+		return fmt.Errorf("goroutine %d failed", id)
+	case <-ctx.Done():
+		return ctx.Err()
+	}
 }
 
 // Function that sends data to a channel
@@ -109,18 +127,55 @@ func mutexExample() {
 func main() {
 	fmt.Println("Concurrency in Go:")
 
-	// Basic goroutines with WaitGroup
+	// Basic goroutines with a Group, at most two at a time
 	fmt.Println("\n=== Basic Goroutines ===")
-	var wg sync.WaitGroup
+	group, ctx := NewGroup(context.Background(), 2, false)
 	for i := 1; i <= 3; i++ {
-		wg.Add(1)
-		go sayHello(i, &wg)
+		id := i // Copy the loop variable for the closure
+		group.Go(func() error { return sayHello(ctx, id) })
 	}
 
 	// Wait for all goroutines to finish
-	wg.Wait()
+	if err := group.Wait(); err != nil {
+		fmt.Println("Error:", err)
+	}
 	fmt.Println("All goroutines completed")
 
+	// Errors and panics from goroutines
+	fmt.Println("\n=== Goroutine Errors ===")
+	group, ctx = NewGroup(context.Background(), 0, false)
+	group.Go(func() error { return failAfter(ctx, 1, time.Millisecond*10) })
+	group.Go(func() error { return failAfter(ctx, 2, time.Millisecond*20) })
+	group.Go(func() error { panic("something went wrong") })
+	err := group.Wait()
+
+	// All three errors are joined together; the panic keeps its stack trace
+	if joined, ok := err.(interface{ Unwrap() []error }); ok {
+		fmt.Println("Errors reported:", len(joined.Unwrap()))
+	}
+	var panicErr *PanicError
+	if errors.As(err, &panicErr) {
+		fmt.Println("Recovered panic:", panicErr)
+		fmt.Println("Stack trace captured:", len(panicErr.Stack) > 0)
+	}
+
+	// In fail-fast mode the first error cancels the rest
+	group, ctx = NewGroup(context.Background(), 0, true)
+	group.Go(func() error { return failAfter(ctx, 1, time.Millisecond*10) })
+	group.Go(func() error { return sayHello(ctx, 5) })
+	fmt.Println("First error:", group.Wait())
+
+	// Once the group has failed, later work is not started. With a limit
+	// of one, the second Go waits for the first goroutine to fail.
+	group, ctx = NewGroup(context.Background(), 1, true)
+	group.Go(func() error { return failAfter(ctx, 3, 0) })
+	started := false
+	group.Go(func() error {
+		started = true
+		return nil
+	})
+	fmt.Println("Error:", group.Wait(), "- later goroutine started:", started)
+
 	// Channels example
 	fmt.Println("\n=== Channels Example ===")
 	numberChan := make(chan int)